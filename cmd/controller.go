@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/resource"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/usecase"
+	cfg "github.com/marcosQuesada/prometheus-operator/pkg/config"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/clientset/versioned"
+	clientgokubescheme "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/clientset/versioned/scheme"
+	crdinformers "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/informers/externalversions"
+	ht "github.com/marcosQuesada/prometheus-operator/pkg/http/handler"
+	"github.com/marcosQuesada/prometheus-operator/pkg/operator"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const prometheusServerOperatorUserAgent = "prometheus-server-controller"
+const httpReadTimeout = 10 * time.Second
+const httpWriteTimeout = 10 * time.Second
+
+// runController registers CRD, wires informers, enforcers and use cases, and runs controller until termination
+func runController(clientSet kubernetes.Interface, pmClientSet versioned.Interface, api apiextensionsclientset.Interface, inCluster bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := crd.NewManager(api)
+	if err := crd.NewBuilder(m).EnsureCRDRegistration(ctx); err != nil {
+		log.Fatalf("unable to ensure prometheus server crd registration, error %v", err)
+	}
+
+	crdInf := crdinformers.NewSharedInformerFactory(pmClientSet, reSyncInterval)
+	shInf := informers.NewSharedInformerFactory(clientSet, 0)
+	nsInf := informers.NewSharedInformerFactoryWithOptions(clientSet, 0, informers.WithNamespace(service.MonitoringNamespace))
+
+	ps := crdInf.K8slab().V1alpha1().PrometheusServers().Informer()
+	cr := shInf.Rbac().V1().ClusterRoles().Informer()
+	crb := shInf.Rbac().V1().ClusterRoleBindings().Informer()
+	cm := shInf.Core().V1().ConfigMaps().Informer()
+	sts := shInf.Apps().V1().StatefulSets().Informer()
+	svc := shInf.Core().V1().Services().Informer()
+	pvc := shInf.Core().V1().PersistentVolumeClaims().Informer()
+	ing := shInf.Networking().V1().Ingresses().Informer()
+	sa := shInf.Core().V1().ServiceAccounts().Informer()
+	rl := shInf.Rbac().V1().Roles().Informer()
+	rlb := shInf.Rbac().V1().RoleBindings().Informer()
+	np := shInf.Networking().V1().NetworkPolicies().Informer()
+	pdb := shInf.Policy().V1().PodDisruptionBudgets().Informer()
+	ds := shInf.Apps().V1().DaemonSets().Informer()
+	dp := shInf.Apps().V1().Deployments().Informer()
+	sc := nsInf.Core().V1().Secrets().Informer()
+	po := nsInf.Core().V1().Pods().Informer()
+
+	crdInf.Start(ctx.Done())
+	shInf.Start(ctx.Done())
+	nsInf.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(),
+		cr.HasSynced,
+		crb.HasSynced,
+		cm.HasSynced,
+		sts.HasSynced,
+		svc.HasSynced,
+		pvc.HasSynced,
+		ing.HasSynced,
+		sa.HasSynced,
+		rl.HasSynced,
+		rlb.HasSynced,
+		np.HasSynced,
+		pdb.HasSynced,
+		ds.HasSynced,
+		dp.HasSynced,
+		sc.HasSynced,
+		po.HasSynced) {
+		log.Fatal("unable to sync informers")
+	}
+
+	secrets := nsInf.Core().V1().Secrets().Lister()
+	rev := resource.NewConfigRevisions(clientSet, shInf.Core().V1().ConfigMaps().Lister())
+	r := []service.ResourceEnforcer{
+		resource.NewServiceAccount(clientSet, shInf.Core().V1().ServiceAccounts().Lister()),
+		resource.NewClusterRole(clientSet, shInf.Rbac().V1().ClusterRoles().Lister()),
+		resource.NewClusterRoleBinding(clientSet, shInf.Rbac().V1().ClusterRoleBindings().Lister()),
+		resource.NewRole(clientSet, shInf.Rbac().V1().Roles().Lister()),
+		resource.NewRoleBinding(clientSet, shInf.Rbac().V1().RoleBindings().Lister()),
+		resource.NewConfigMap(clientSet, shInf.Core().V1().ConfigMaps().Lister(), crdInf.K8slab().V1alpha1().PrometheusServers().Lister(), prometheusExternalLabels(), resource.TemplateVars(templateVars)),
+		rev,
+		resource.NewWebConfig(clientSet, secrets),
+		resource.NewHeadlessService(clientSet, shInf.Core().V1().Services().Lister()),
+		resource.NewStatefulSet(clientSet, shInf.Apps().V1().StatefulSets().Lister(), shInf.Core().V1().PersistentVolumeClaims().Lister(), nsInf.Core().V1().Pods().Lister(), prometheusImage(), resource.NewPodClients(secrets)),
+		resource.NewService(clientSet, shInf.Core().V1().Services().Lister()),
+		resource.NewIngress(clientSet, shInf.Networking().V1().Ingresses().Lister()),
+		resource.NewNetworkPolicy(clientSet, shInf.Networking().V1().NetworkPolicies().Lister()),
+		resource.NewPodDisruptionBudget(clientSet, shInf.Policy().V1().PodDisruptionBudgets().Lister()),
+		resource.NewNodeExporter(clientSet, shInf.Apps().V1().DaemonSets().Lister()),
+		resource.NewKubeStateMetrics(clientSet, shInf.Apps().V1().Deployments().Lister()),
+	}
+	re := service.NewResource(r...)
+	generationCache := service.NewGenerationCache()
+	fnlz := service.NewFinalizer(pmClientSet)
+	rec := createRecorder(clientSet, prometheusServerOperatorUserAgent)
+	vp := prometheusVersionPolicy()
+	cnlt := service.NewConciliator()
+	cnlt.Register(usecase.NewCreator(fnlz, re, rec, vp))
+	cnlt.Register(usecase.NewDeleter(fnlz, re, rec))
+	cnlt.Register(usecase.NewReloader(generationCache, re, rec, vp))
+	cnlt.Register(usecase.NewRollout(re, rec, inCluster))
+
+	op := service.NewOperator(crdInf.K8slab().V1alpha1().PrometheusServers().Lister(), pmClientSet, generationCache, cnlt, rev)
+	ctl := operator.NewController(op, ps)
+	go ctl.Run(ctx)
+
+	router := mux.NewRouter()
+	ch := ht.NewChecker(cfg.Commit, cfg.Date)
+	ch.Routes(router)
+	router.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.HttpPort),
+		Handler:      router,
+		ReadTimeout:  httpReadTimeout,
+		WriteTimeout: httpWriteTimeout,
+	}
+
+	go func(h *http.Server) {
+		e := h.ListenAndServe()
+		if e != nil && e != http.ErrServerClosed {
+			log.Fatalf("Could not Listen and server, error %v", e)
+		}
+	}(srv)
+
+	sigTerm := make(chan os.Signal, 1)
+	signal.Notify(sigTerm, syscall.SIGTERM, syscall.SIGINT)
+	<-sigTerm
+	if err := srv.Close(); err != nil {
+		log.Errorf("unexpected error on http server close %v", err)
+	}
+	cancel()
+	_ = srv.Close()
+
+	log.Info("Stopping controller")
+}
+
+func createRecorder(kubeClient kubernetes.Interface, userAgent string) record.EventRecorder {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&corev1client.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(clientgokubescheme.Scheme, v1.EventSource{Component: userAgent})
+}
@@ -3,11 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/marcosQuesada/prometheus-operator/internal/service/resource"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
 	cfg "github.com/marcosQuesada/prometheus-operator/pkg/config"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const appID = "prometheus-operator"
@@ -16,6 +20,16 @@ var (
 	namespace      string
 	watchLabel     string
 	reSyncInterval time.Duration
+
+	imageRepository  string
+	imagePullPolicy  string
+	imagePullSecrets []string
+
+	clusterName          string
+	externalLabelsPolicy string
+	templateVars         map[string]string
+
+	allowedVersions string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -43,6 +57,45 @@ func init() {
 	cobra.OnInitialize(initConfig)
 	cfg.SetCoreFlags(rootCmd, appID)
 
+	rootCmd.PersistentFlags().StringVar(&imageRepository, "image-repository", resource.DefaultImageRepository, "prometheus image repository")
+	if p := os.Getenv("IMAGE_REPOSITORY"); p != "" {
+		imageRepository = p
+	}
+	rootCmd.PersistentFlags().StringVar(&imagePullPolicy, "image-pull-policy", "", "prometheus image pull policy")
+	if p := os.Getenv("IMAGE_PULL_POLICY"); p != "" {
+		imagePullPolicy = p
+	}
+	rootCmd.PersistentFlags().StringSliceVar(&imagePullSecrets, "image-pull-secrets", nil, "prometheus image pull secret names")
+	if p := os.Getenv("IMAGE_PULL_SECRETS"); p != "" {
+		imagePullSecrets = strings.Split(p, ",")
+	}
+
+	rootCmd.PersistentFlags().StringVar(&clusterName, "cluster-name", "", "cluster external label added to every prometheus config")
+	if p := os.Getenv("CLUSTER_NAME"); p != "" {
+		clusterName = p
+	}
+	rootCmd.PersistentFlags().StringVar(&externalLabelsPolicy, "external-labels-policy", resource.OverrideLabelPolicy, "operator managed external labels policy on user defined ones (override or reject)")
+	if p := os.Getenv("EXTERNAL_LABELS_POLICY"); p != "" {
+		externalLabelsPolicy = p
+	}
+
+	rootCmd.PersistentFlags().StringToStringVar(&templateVars, "template-vars", nil, "variables available on prometheus config templates (key=value)")
+	if p := os.Getenv("TEMPLATE_VARS"); p != "" {
+		templateVars = map[string]string{}
+		for _, kv := range strings.Split(p, ",") {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				log.Fatalf("invalid template var %s, expected key=value", kv)
+			}
+			templateVars[parts[0]] = parts[1]
+		}
+	}
+
+	rootCmd.PersistentFlags().StringVar(&allowedVersions, "allowed-versions", "", "allowed prometheus versions range, as space separated constraints (\">=v2.30.0 <v3.0.0\")")
+	if p := os.Getenv("ALLOWED_VERSIONS"); p != "" {
+		allowedVersions = p
+	}
+
 	var i string
 	i = *rootCmd.PersistentFlags().StringP("resync-interval", "r", "5s", "informer resync interval")
 	var err error
@@ -54,3 +107,33 @@ func init() {
 		log.Fatalf("Invalid interval duration %s, error %v", i, err)
 	}
 }
+
+// prometheusImage builds operator level Prometheus image settings
+func prometheusImage() resource.Image {
+	return resource.Image{
+		Repository:  imageRepository,
+		PullPolicy:  corev1.PullPolicy(imagePullPolicy),
+		PullSecrets: imagePullSecrets,
+	}
+}
+
+// prometheusExternalLabels builds operator level external labels settings
+func prometheusExternalLabels() resource.ExternalLabels {
+	if externalLabelsPolicy != resource.OverrideLabelPolicy && externalLabelsPolicy != resource.RejectLabelPolicy {
+		log.Fatalf("invalid external labels policy %s", externalLabelsPolicy)
+	}
+
+	return resource.ExternalLabels{
+		Cluster: clusterName,
+		Policy:  externalLabelsPolicy,
+	}
+}
+
+// prometheusVersionPolicy builds operator level version policy from allowed versions range
+func prometheusVersionPolicy() version.Policy {
+	p, err := version.NewPolicy(allowedVersions)
+	if err != nil {
+		log.Fatalf("invalid allowed versions %s, error %v", allowedVersions, err)
+	}
+	return p
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/clientset/versioned"
 	v1alpha1Lister "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/listers/prometheusserver/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,20 +31,27 @@ type Cache interface {
 	Remove(namespace, name string)
 }
 
+// SpecResolver resolves PrometheusServer desired spec before conciliation, resolved spec is never persisted
+type SpecResolver interface {
+	Resolve(ps *v1alpha1.PrometheusServer) error
+}
+
 type operator struct {
 	lister          v1alpha1Lister.PrometheusServerLister
 	client          versioned.Interface
 	generationCache Cache
 	conciliator     Conciliator
+	resolver        SpecResolver
 }
 
 // NewOperator instantiates Prometheus Server controller
-func NewOperator(l v1alpha1Lister.PrometheusServerLister, cl versioned.Interface, g Cache, c Conciliator) op.Handler {
+func NewOperator(l v1alpha1Lister.PrometheusServerLister, cl versioned.Interface, g Cache, c Conciliator, r SpecResolver) op.Handler {
 	return &operator{
 		lister:          l,
 		client:          cl,
 		generationCache: g,
 		conciliator:     c,
+		resolver:        r,
 	}
 }
 
@@ -51,10 +59,12 @@ func NewOperator(l v1alpha1Lister.PrometheusServerLister, cl versioned.Interface
 func (o *operator) Update(ctx context.Context, namespace, name string) error {
 	defer updatesProcessed.Inc()
 
-	ps, err := o.lister.PrometheusServers(namespace).Get(name)
+	current, err := o.lister.PrometheusServers(namespace).Get(name)
 	if err != nil {
 		return fmt.Errorf("unable to get prometheus server  on namespace %s name %s definition , error %w", namespace, name, err)
 	}
+	// conciliation handlers are allowed to report status fields, work on a copy to not mutate informer cache
+	ps := current.DeepCopy()
 	log.Infof("Update called namespace %s name %s Status %s ", ps.Namespace, ps.Name, ps.Status.Phase)
 
 	defer o.generationCache.Set(namespace, name, ps.Generation)
@@ -66,17 +76,33 @@ func (o *operator) Update(ctx context.Context, namespace, name string) error {
 		return nil
 	}
 
+	if ps.DeletionTimestamp.IsZero() {
+		if err := o.resolver.Resolve(ps); err != nil {
+			return fmt.Errorf("unable to resolve spec, error %w", err)
+		}
+	}
+
 	newState, err := o.conciliator.Conciliate(ctx, ps)
 	if err != nil {
+		// status reported by failing handlers, as conditions, is kept as it explains the failure
+		if !equality.Semantic.DeepEqual(current.Status, ps.Status) {
+			if err := o.updateStatus(ctx, ps, ps.Status.Phase); err != nil {
+				log.Errorf("unable to update status on conciliation failure, error %v", err)
+			}
+		}
 		return fmt.Errorf("unable to conciliate, error %w", err)
 	}
 
-	if ps.Status.Phase == newState || newState == v1alpha1.Terminated {
+	if newState == v1alpha1.Terminated {
+		return nil
+	}
+
+	if current.Status.Phase == newState && equality.Semantic.DeepEqual(current.Status, ps.Status) {
 		return nil
 	}
 
 	if err := o.updateStatus(ctx, ps, newState); err != nil {
-		return fmt.Errorf("unable to update status from %s to %s, error %w", ps.Status.Phase, newState, err)
+		return fmt.Errorf("unable to update status from %s to %s, error %w", current.Status.Phase, newState, err)
 	}
 	return nil
 }
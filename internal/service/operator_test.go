@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -29,7 +30,7 @@ func TestItJumpsToNewStateWhenConciliationResultHasDifferentState(t *testing.T)
 
 	gc := &fakeCache{value: 1}
 	c := &fakeConciliator{newState: v1alpha1.Running}
-	o := NewOperator(pi.Lister(), pmClientSet, gc, c)
+	o := NewOperator(pi.Lister(), pmClientSet, gc, c, &fakeResolver{})
 
 	if err := o.Update(context.Background(), namespace, name); err != nil {
 		t.Fatalf("unexpected error updating, %v", err)
@@ -80,7 +81,7 @@ func TestItSetTerminatingStateOnPrometheusServerUpdateWithDeletionTimestamp(t *t
 
 	gc := &fakeCache{value: 1}
 	c := &fakeConciliator{newState: v1alpha1.Running}
-	o := NewOperator(pi.Lister(), pmClientSet, gc, c)
+	o := NewOperator(pi.Lister(), pmClientSet, gc, c, &fakeResolver{})
 
 	if err := o.Update(context.Background(), namespace, name); err != nil {
 		t.Fatalf("unexpected error updating, %v", err)
@@ -130,7 +131,7 @@ func TestItRemainsItsStateWhenConciliationReturnsSameCrdState(t *testing.T) {
 
 	gc := &fakeCache{value: 1}
 	c := &fakeConciliator{newState: v1alpha1.Running}
-	o := NewOperator(pi.Lister(), pmClientSet, gc, c)
+	o := NewOperator(pi.Lister(), pmClientSet, gc, c, &fakeResolver{})
 
 	if err := o.Update(context.Background(), namespace, name); err != nil {
 		t.Fatalf("unexpected error updating, %v", err)
@@ -143,6 +144,122 @@ func TestItRemainsItsStateWhenConciliationReturnsSameCrdState(t *testing.T) {
 
 }
 
+func TestItUpdatesStatusWhenConciliationReportsStatusChangesOnSameCrdState(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	pm := getFakePrometheusServer(namespace, name)
+	pmClientSet := crdFake.NewSimpleClientset(pm)
+	crdInf := crdinformers.NewSharedInformerFactory(pmClientSet, 0)
+	pi := crdInf.K8slab().V1alpha1().PrometheusServers()
+
+	ps := getFakePrometheusServer(namespace, name)
+	ps.Status.Phase = v1alpha1.Running
+
+	if err := pi.Informer().GetIndexer().Add(ps); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	gc := &fakeCache{value: 1}
+	c := &fakeConciliator{newState: v1alpha1.Running, conditions: []metav1.Condition{{Type: v1alpha1.StorageSynced, Status: metav1.ConditionTrue}}}
+	o := NewOperator(pi.Lister(), pmClientSet, gc, c, &fakeResolver{})
+
+	if err := o.Update(context.Background(), namespace, name); err != nil {
+		t.Fatalf("unexpected error updating, %v", err)
+	}
+
+	clActions := pmClientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	v, ok := clActions[0].(k8stest.UpdateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	ups, ok := v.GetObject().(*v1alpha1.PrometheusServer)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	if expected, got := 1, len(ups.Status.Conditions); expected != got {
+		t.Fatalf("conditions does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 0, len(ps.Status.Conditions); expected != got {
+		t.Fatalf("informer cache entry mutated, expected %d got %d", expected, got)
+	}
+}
+
+func TestItUpdatesStatusReportedByFailingConciliation(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	pm := getFakePrometheusServer(namespace, name)
+	pmClientSet := crdFake.NewSimpleClientset(pm)
+	crdInf := crdinformers.NewSharedInformerFactory(pmClientSet, 0)
+	pi := crdInf.K8slab().V1alpha1().PrometheusServers()
+
+	ps := getFakePrometheusServer(namespace, name)
+	ps.Status.Phase = v1alpha1.Initializing
+
+	if err := pi.Informer().GetIndexer().Add(ps); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	gc := &fakeCache{value: 1}
+	cond := metav1.Condition{Type: v1alpha1.ConfigRendered, Status: metav1.ConditionFalse, Reason: "RenderFailed", Message: "foo error"}
+	c := &fakeConciliator{newState: v1alpha1.Initializing, conditions: []metav1.Condition{cond}, error: errors.New("foo error")}
+	o := NewOperator(pi.Lister(), pmClientSet, gc, c, &fakeResolver{})
+
+	if err := o.Update(context.Background(), namespace, name); err == nil {
+		t.Fatal("expected conciliation error")
+	}
+
+	clActions := pmClientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	ups, ok := clActions[0].(k8stest.UpdateAction).GetObject().(*v1alpha1.PrometheusServer)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	if expected, got := v1alpha1.Initializing, ups.Status.Phase; expected != got {
+		t.Errorf("phase does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, len(ups.Status.Conditions); expected != got {
+		t.Fatalf("conditions size does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := cond.Message, ups.Status.Conditions[0].Message; expected != got {
+		t.Errorf("condition message does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItFailsUpdateOnUnresolvedSpec(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	pm := getFakePrometheusServer(namespace, name)
+	pmClientSet := crdFake.NewSimpleClientset(pm)
+	crdInf := crdinformers.NewSharedInformerFactory(pmClientSet, 0)
+	pi := crdInf.K8slab().V1alpha1().PrometheusServers()
+
+	ps := getFakePrometheusServer(namespace, name)
+	ps.Status.Phase = v1alpha1.Running
+
+	if err := pi.Informer().GetIndexer().Add(ps); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	c := &fakeConciliator{newState: v1alpha1.Reloading}
+	o := NewOperator(pi.Lister(), pmClientSet, &fakeCache{value: 1}, c, &fakeResolver{error: errors.New("revision not found")})
+
+	if err := o.Update(context.Background(), namespace, name); err == nil {
+		t.Fatal("expected resolve error")
+	}
+
+	if expected, got := 0, len(pmClientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+}
+
 type fakeCache struct {
 	value   int64
 	set     int
@@ -162,10 +279,20 @@ func (f *fakeCache) Remove(namespace, name string) {
 }
 
 type fakeConciliator struct {
-	newState string
-	error    error
+	newState   string
+	conditions []metav1.Condition
+	error      error
 }
 
 func (f *fakeConciliator) Conciliate(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
+	ps.Status.Conditions = append(ps.Status.Conditions, f.conditions...)
 	return f.newState, f.error
 }
+
+type fakeResolver struct {
+	error error
+}
+
+func (f *fakeResolver) Resolve(ps *v1alpha1.PrometheusServer) error {
+	return f.error
+}
@@ -0,0 +1,129 @@
+package promapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const targetsEndpoint = "/api/v1/targets"
+const snapshotEndpoint = "/api/v1/admin/tsdb/snapshot"
+const reloadEndpoint = "/-/reload"
+const statusSuccess = "success"
+
+// HealthUp is reported by targets scraped successfully on their last scrape
+const HealthUp = "up"
+
+// Target is a Prometheus active scrape target
+type Target struct {
+	ScrapePool string `json:"scrapePool"`
+	ScrapeURL  string `json:"scrapeUrl"`
+	Health     string `json:"health"`
+	LastError  string `json:"lastError"`
+}
+
+// Client requests Prometheus HTTP API, basic auth credentials are sent when username is defined
+type Client struct {
+	url      string
+	http     *http.Client
+	username string
+	password string
+}
+
+// NewClient instantiates Prometheus API client, url includes scheme, host and route prefix
+func NewClient(url string, cl *http.Client, username, password string) *Client {
+	return &Client{
+		url:      strings.TrimSuffix(url, "/"),
+		http:     cl,
+		username: username,
+		password: password,
+	}
+}
+
+// Targets returns active scrape targets
+func (c *Client) Targets(ctx context.Context) ([]Target, error) {
+	var data struct {
+		ActiveTargets []Target `json:"activeTargets"`
+	}
+	if err := c.do(ctx, http.MethodGet, targetsEndpoint+"?state=active", &data); err != nil {
+		return nil, err
+	}
+	return data.ActiveTargets, nil
+}
+
+// Snapshot creates a TSDB snapshot and returns its name, it requires admin API enabled. Head block data is included
+func (c *Client) Snapshot(ctx context.Context) (string, error) {
+	var data struct {
+		Name string `json:"name"`
+	}
+	if err := c.do(ctx, http.MethodPost, snapshotEndpoint, &data); err != nil {
+		return "", err
+	}
+	return data.Name, nil
+}
+
+// Reload requests config reload, it requires lifecycle API enabled. Config load errors are reported on response
+func (c *Client) Reload(ctx context.Context) error {
+	raw, code, err := c.request(ctx, http.MethodPost, reloadEndpoint)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return fmt.Errorf("request %s failed, status code %d error %s", reloadEndpoint, code, strings.TrimSpace(string(raw)))
+	}
+	return nil
+}
+
+// do executes API request, response data is decoded on v when it's not nil
+func (c *Client) do(ctx context.Context, method, endpoint string, v interface{}) error {
+	raw, code, err := c.request(ctx, method, endpoint)
+	if err != nil {
+		return err
+	}
+
+	var r struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return fmt.Errorf("unexpected %s response, status code %d", endpoint, code)
+	}
+	if r.Status != statusSuccess {
+		return fmt.Errorf("request %s failed, status code %d error %s", endpoint, code, r.Error)
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("unable to decode %s response, error %w", endpoint, err)
+	}
+	return nil
+}
+
+// request executes http request, returning response body and status code
+func (c *Client) request(ctx context.Context, method, endpoint string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+endpoint, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to build request %s, error %w", endpoint, err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to request %s, error %w", endpoint, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to read %s response, error %w", endpoint, err)
+	}
+	return raw, res.StatusCode, nil
+}
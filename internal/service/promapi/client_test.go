@@ -0,0 +1,110 @@
+package promapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestItGetsActiveTargetsWithBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "foo" || p != "bar" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/prometheus/api/v1/targets" || r.URL.Query().Get("state") != "active" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"activeTargets":[{"scrapePool":"node","health":"up"},{"scrapePool":"pods","health":"down","lastError":"timeout"}]}}`))
+	}))
+	defer srv.Close()
+
+	targets, err := NewClient(srv.URL+"/prometheus/", srv.Client(), "foo", "bar").Targets(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting targets %v", err)
+	}
+	if expected, got := 2, len(targets); expected != got {
+		t.Fatalf("targets do not match, expected %d got %d", expected, got)
+	}
+	if targets[0].Health != HealthUp || targets[1].LastError != "timeout" {
+		t.Errorf("unexpected targets %v", targets)
+	}
+}
+
+func TestItFailsOnUnauthorizedRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, srv.Client(), "", "").Targets(context.Background()); err == nil {
+		t.Fatal("expected unauthorized error")
+	}
+}
+
+func TestItFailsOnAPIErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"invalid state"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, srv.Client(), "", "").Targets(context.Background()); err == nil {
+		t.Fatal("expected api error")
+	}
+}
+
+func TestItCreatesTSDBSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/admin/tsdb/snapshot" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"name":"20220510T101010Z-6a1b2c3d4e5f6a7b"}}`))
+	}))
+	defer srv.Close()
+
+	name, err := NewClient(srv.URL, srv.Client(), "", "").Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error creating snapshot %v", err)
+	}
+	if expected, got := "20220510T101010Z-6a1b2c3d4e5f6a7b", name; expected != got {
+		t.Errorf("snapshot name does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItFailsSnapshotWithAdminAPIDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnavailableForLegalReasons)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"unavailable","error":"admin APIs disabled"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, srv.Client(), "", "").Snapshot(context.Background()); err == nil {
+		t.Fatal("expected admin api disabled error")
+	}
+}
+
+func TestItRequestsConfigReload(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/-/reload" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			_, _ = w.Write([]byte("failed to reload config: yaml: line 2\n"))
+		}
+	}))
+	defer srv.Close()
+
+	cl := NewClient(srv.URL, srv.Client(), "", "")
+	if err := cl.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error reloading %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if err := cl.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+}
@@ -0,0 +1,253 @@
+package promconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+const globalKey = "global"
+const externalLabelsKey = "external_labels"
+const scrapeConfigsKey = "scrape_configs"
+const jobNameKey = "job_name"
+const kubernetesSDConfigsKey = "kubernetes_sd_configs"
+const namespacesKey = "namespaces"
+const namesKey = "names"
+const relabelConfigsKey = "relabel_configs"
+
+// Config wraps raw Prometheus configuration, allowing operator managed changes while keeping user sections order
+type Config struct {
+	root yaml.MapSlice
+}
+
+// Parse builds Config from raw Prometheus yaml configuration
+func Parse(raw string) (*Config, error) {
+	root := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(raw), &root); err != nil {
+		return nil, fmt.Errorf("unable to parse prometheus config, error %w", err)
+	}
+
+	return &Config{root: root}, nil
+}
+
+// SetExternalLabel adds or replaces a global external label
+func (c *Config) SetExternalLabel(name, value string) {
+	global := section(c.root, globalKey)
+	labels := section(global, externalLabelsKey)
+	labels = set(labels, name, value)
+	global = set(global, externalLabelsKey, labels)
+	c.root = set(c.root, globalKey, global)
+}
+
+// HasExternalLabel checks if global external label is defined
+func (c *Config) HasExternalLabel(name string) bool {
+	_, ok := get(section(section(c.root, globalKey), externalLabelsKey), name)
+	return ok
+}
+
+// RestrictKubernetesSDNamespaces limits kubernetes service discovery to allowed namespaces
+func (c *Config) RestrictKubernetesSDNamespaces(allowed []string) error {
+	isAllowed := map[string]bool{}
+	values := make([]interface{}, 0, len(allowed))
+	for _, ns := range allowed {
+		isAllowed[ns] = true
+		values = append(values, ns)
+	}
+
+	// sequences share backing arrays with its parent map, items are replaced in place
+	for _, sc := range sequence(c.root, scrapeConfigsKey) {
+		job, ok := sc.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		sdConfigs := sequence(job, kubernetesSDConfigsKey)
+		for j, sd := range sdConfigs {
+			sdc, ok := sd.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+
+			namespaces := section(sdc, namespacesKey)
+			names := sequence(namespaces, namesKey)
+			for _, n := range names {
+				if ns, ok := n.(string); !ok || !isAllowed[ns] {
+					name, _ := get(job, jobNameKey)
+					return fmt.Errorf("job %v discovers namespace %v out of rbac scope", name, n)
+				}
+			}
+			if len(names) > 0 {
+				continue
+			}
+
+			sdConfigs[j] = set(sdc, namespacesKey, set(namespaces, namesKey, values))
+		}
+	}
+
+	return nil
+}
+
+// AppendRelabelConfigs adds relabel configs at the end of each scrape job, after user defined ones
+func (c *Config) AppendRelabelConfigs(values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	// sequences share backing arrays with its parent map, items are replaced in place
+	scrapeConfigs := sequence(c.root, scrapeConfigsKey)
+	for i, sc := range scrapeConfigs {
+		job, ok := sc.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		scrapeConfigs[i] = set(job, relabelConfigsKey, append(sequence(job, relabelConfigsKey), values...))
+	}
+}
+
+// Append adds values at the end of a top level list section, as remote_write
+func (c *Config) Append(key string, values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	c.root = set(c.root, key, append(sequence(c.root, key), values...))
+}
+
+// Merge adds values to a top level list section, entries with the same field value are replaced in place
+func (c *Config) Merge(key, field string, values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	entries := sequence(c.root, key)
+	for _, v := range values {
+		if i := index(entries, field, v); i >= 0 {
+			entries[i] = v
+			continue
+		}
+		entries = append(entries, v)
+	}
+	c.root = set(c.root, key, entries)
+}
+
+// Has checks if top level section is defined
+func (c *Config) Has(key string) bool {
+	_, ok := get(c.root, key)
+	return ok
+}
+
+// Keys returns top level section keys, in order
+func (c *Config) Keys() []string {
+	var res []string
+	for _, item := range c.root {
+		if k, ok := item.Key.(string); ok {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+// Values returns top level list section values, none when section is not a list
+func (c *Config) Values(key string) []interface{} {
+	return sequence(c.root, key)
+}
+
+// JobNames returns scrape job names, in order
+func (c *Config) JobNames() []string {
+	var res []string
+	for _, sc := range sequence(c.root, scrapeConfigsKey) {
+		job, ok := sc.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		name, _ := get(job, jobNameKey)
+		res = append(res, fmt.Sprintf("%v", name))
+	}
+	return res
+}
+
+// Marshal returns Prometheus yaml configuration
+func (c *Config) Marshal() (string, error) {
+	raw, err := yaml.Marshal(c.root)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal prometheus config, error %w", err)
+	}
+
+	return string(raw), nil
+}
+
+// section returns key value as map, empty one is returned when key is not found
+func section(m yaml.MapSlice, key string) yaml.MapSlice {
+	v, ok := get(m, key)
+	if !ok {
+		return yaml.MapSlice{}
+	}
+
+	s, ok := v.(yaml.MapSlice)
+	if !ok {
+		return yaml.MapSlice{}
+	}
+	return s
+}
+
+// sequence returns key value as list, empty one is returned when key is not found
+func sequence(m yaml.MapSlice, key string) []interface{} {
+	v, ok := get(m, key)
+	if !ok {
+		return nil
+	}
+
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	return s
+}
+
+// index returns first entry matching value by field, -1 when there's none
+func index(entries []interface{}, field string, value interface{}) int {
+	id, ok := entryID(value, field)
+	if !ok {
+		return -1
+	}
+	for i, e := range entries {
+		if eid, ok := entryID(e, field); ok && eid == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// entryID returns map entry field value, plain entries are identified by their own value
+func entryID(entry interface{}, field string) (string, bool) {
+	m, ok := entry.(yaml.MapSlice)
+	if !ok {
+		return fmt.Sprintf("%v", entry), true
+	}
+
+	v, ok := get(m, field)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%v", v), true
+}
+
+func get(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if k, ok := item.Key.(string); ok && k == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set updates key value in place or appends it at the end
+func set(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range m {
+		if k, ok := item.Key.(string); ok && k == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
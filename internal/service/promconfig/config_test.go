@@ -0,0 +1,227 @@
+package promconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestItAddsExternalLabelKeepingUserConfig(t *testing.T) {
+	raw := `global:
+  scrape_interval: 5s
+  external_labels:
+    cluster: foo
+scrape_configs:
+- job_name: bar
+`
+	c, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c.SetExternalLabel("replica", "${POD_NAME}")
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `global:
+  scrape_interval: 5s
+  external_labels:
+    cluster: foo
+    replica: ${POD_NAME}
+scrape_configs:
+- job_name: bar
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItAddsGlobalSectionOnExternalLabelWithoutGlobalConfig(t *testing.T) {
+	c, err := Parse("scrape_configs: []\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c.SetExternalLabel("replica", "${POD_NAME}")
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `scrape_configs: []
+global:
+  external_labels:
+    replica: ${POD_NAME}
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItFailsParsingInvalidConfig(t *testing.T) {
+	if _, err := Parse("global: [foo"); err == nil {
+		t.Fatal("expected parsing error")
+	}
+}
+
+func TestItRestrictsKubernetesServiceDiscoveryToAllowedNamespaces(t *testing.T) {
+	raw := `scrape_configs:
+- job_name: pods
+  kubernetes_sd_configs:
+  - role: pod
+- job_name: static
+  static_configs:
+  - targets: [localhost:9090]
+`
+	c, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	if err := c.RestrictKubernetesSDNamespaces([]string{"team-a", "team-b"}); err != nil {
+		t.Fatalf("unexpected error restricting namespaces %v", err)
+	}
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: pods
+  kubernetes_sd_configs:
+  - role: pod
+    namespaces:
+      names:
+      - team-a
+      - team-b
+- job_name: static
+  static_configs:
+  - targets:
+    - localhost:9090
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItRejectsKubernetesServiceDiscoveryOnNotAllowedNamespaces(t *testing.T) {
+	raw := `scrape_configs:
+- job_name: pods
+  kubernetes_sd_configs:
+  - role: pod
+    namespaces:
+      names: [kube-system]
+`
+	c, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	if err := c.RestrictKubernetesSDNamespaces([]string{"team-a"}); err == nil {
+		t.Fatal("expected error on not allowed namespace")
+	}
+}
+
+func TestItAppendsValuesToTopLevelListSection(t *testing.T) {
+	c, err := Parse("remote_write:\n- url: http://foo\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c.Append("remote_write", yaml.MapSlice{{Key: "url", Value: "http://bar"}})
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := "remote_write:\n- url: http://foo\n- url: http://bar\n"
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItMergesValuesToTopLevelListSectionByField(t *testing.T) {
+	c, err := Parse("scrape_configs:\n- job_name: foo\n  scrape_interval: 5s\n- job_name: bar\nrule_files:\n- a.yml\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c.Merge("scrape_configs", "job_name", yaml.MapSlice{{Key: "job_name", Value: "foo"}, {Key: "scrape_interval", Value: "10s"}}, yaml.MapSlice{{Key: "job_name", Value: "zoo"}})
+	c.Merge("rule_files", "", "a.yml", "b.yml")
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := "scrape_configs:\n- job_name: foo\n  scrape_interval: 10s\n- job_name: bar\n- job_name: zoo\nrule_files:\n- a.yml\n- b.yml\n"
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItAppendsRelabelConfigsToEachScrapeJob(t *testing.T) {
+	raw := `scrape_configs:
+- job_name: foo
+  relabel_configs:
+  - action: labelmap
+    regex: __meta_(.+)
+- job_name: bar
+`
+	c, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c.AppendRelabelConfigs(yaml.MapSlice{{Key: "action", Value: "keep"}})
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: foo
+  relabel_configs:
+  - action: labelmap
+    regex: __meta_(.+)
+  - action: keep
+- job_name: bar
+  relabel_configs:
+  - action: keep
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItChecksExternalLabelExistence(t *testing.T) {
+	c, err := Parse("global:\n  external_labels:\n    cluster: foo\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+
+	if !c.HasExternalLabel("cluster") {
+		t.Error("expected cluster external label")
+	}
+	if c.HasExternalLabel("region") {
+		t.Error("unexpected region external label")
+	}
+}
+
+func TestItReturnsSectionKeysValuesAndJobNames(t *testing.T) {
+	c, err := Parse("scrape_configs:\n- job_name: foo\n- job_name: bar\nrule_files:\n- /etc/rules/*.yml\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+
+	if expected, got := []string{"scrape_configs", "rule_files"}, c.Keys(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("keys do not match, expected %v got %v", expected, got)
+	}
+	if expected, got := 1, len(c.Values("rule_files")); expected != got {
+		t.Errorf("values do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := []string{"foo", "bar"}, c.JobNames(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("job names do not match, expected %v got %v", expected, got)
+	}
+}
@@ -10,10 +10,20 @@ import (
 
 // ResourceManager delegates responsibility on resource creation/removal
 type ResourceManager interface {
-	AllCreated() (bool, error)
+	AllCreated(p *v1alpha1.PrometheusServer) (bool, error)
 	AllRemoved() (bool, error)
 	CreateAll(ctx context.Context, p *v1alpha1.PrometheusServer) error
 	DeleteAll(ctx context.Context, p *v1alpha1.PrometheusServer) error
+	ReportStatus(p *v1alpha1.PrometheusServer) error
+	AnyDrifted(p *v1alpha1.PrometheusServer) (bool, error)
+	UpdateConfig(ctx context.Context, p *v1alpha1.PrometheusServer) (bool, error)
+	Restart(ctx context.Context, p *v1alpha1.PrometheusServer, at string) error
+	AnyFailed(p *v1alpha1.PrometheusServer) (string, error)
+	Snapshot(ctx context.Context, p *v1alpha1.PrometheusServer) error
+	Reload(ctx context.Context, p *v1alpha1.PrometheusServer) error
+	RollOut(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error
+	RolloutReady(ctx context.Context, p *v1alpha1.PrometheusServer, color string) (bool, error)
+	DeleteRollout(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error
 }
 
 // ResourceEnforcer taks care on resource creation/deletion
@@ -24,6 +34,58 @@ type ResourceEnforcer interface {
 	Name() string
 }
 
+// OptionalEnforcer is implemented by resource enforcers whose resource is only required by some PrometheusServer specs
+type OptionalEnforcer interface {
+	IsRequired(obj *v1alpha1.PrometheusServer) bool
+}
+
+// InPlaceEnforcer is implemented by resource enforcers whose resource is only removed on PrometheusServer termination
+type InPlaceEnforcer interface {
+	UpdatesInPlace() bool
+}
+
+// StatusReporter is implemented by resource enforcers which reflect its resource state on PrometheusServer status
+type StatusReporter interface {
+	ReportStatus(obj *v1alpha1.PrometheusServer) error
+}
+
+// DriftDetector is implemented by resource enforcers able to detect live resource divergences from PrometheusServer spec
+type DriftDetector interface {
+	IsDrifted(obj *v1alpha1.PrometheusServer) (bool, error)
+}
+
+// ConfigUpdater is implemented by resource enforcers which update Prometheus config in place, true when updated
+type ConfigUpdater interface {
+	UpdateConfig(ctx context.Context, obj *v1alpha1.PrometheusServer) (bool, error)
+}
+
+// Restarter is implemented by resource enforcers able to restart their workloads, restart is identified by at value
+type Restarter interface {
+	Restart(ctx context.Context, obj *v1alpha1.PrometheusServer, at string) error
+}
+
+// FailureDetector is implemented by resource enforcers able to detect workloads failing to start
+type FailureDetector interface {
+	Failure(obj *v1alpha1.PrometheusServer) (string, error)
+}
+
+// Snapshotter is implemented by resource enforcers able to snapshot Prometheus TSDB, snapshots are reported on status
+type Snapshotter interface {
+	Snapshot(ctx context.Context, obj *v1alpha1.PrometheusServer) error
+}
+
+// ConfigReloader is implemented by resource enforcers able to reload Prometheus config without restarts
+type ConfigReloader interface {
+	Reload(ctx context.Context, obj *v1alpha1.PrometheusServer) error
+}
+
+// RolloutEnforcer is implemented by resource enforcers whose resources are rolled out blue green on each color
+type RolloutEnforcer interface {
+	EnsureColorCreation(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error
+	IsColorReady(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) (bool, error)
+	EnsureColorDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error
+}
+
 type resource struct {
 	builders []ResourceEnforcer
 }
@@ -35,21 +97,29 @@ func NewResource(b ...ResourceEnforcer) ResourceManager {
 	}
 }
 
-// AllCreated checks all resources exists
-func (o *resource) AllCreated() (bool, error) {
-	return o.allResourcesExist(true)
+// AllCreated checks all required resources exists
+func (o *resource) AllCreated(p *v1alpha1.PrometheusServer) (bool, error) {
+	return o.allResourcesExist(o.required(p), true)
 }
 
-// AllRemoved checks none resources exists
+// AllRemoved checks none resources exists, resources updated in place are kept on reloads
 func (o *resource) AllRemoved() (bool, error) {
-	return o.allResourcesExist(false)
+	var builders []ResourceEnforcer
+	for _, r := range o.builders {
+		if ip, ok := r.(InPlaceEnforcer); ok && ip.UpdatesInPlace() {
+			continue
+		}
+		builders = append(builders, r)
+	}
+
+	return o.allResourcesExist(builders, false)
 }
 
 // CreateAll executes resource creation
 func (o *resource) CreateAll(ctx context.Context, p *v1alpha1.PrometheusServer) error {
 	log.Infof("Creating resources from prometheus server on namespace %s name %s ", p.Namespace, p.Name)
 
-	for _, r := range o.builders {
+	for _, r := range o.required(p) {
 		if err := r.EnsureCreation(ctx, p); err != nil {
 			return fmt.Errorf("unable to ensure creation on %s error %w", r.Name(), err)
 		}
@@ -73,8 +143,181 @@ func (o *resource) DeleteAll(ctx context.Context, p *v1alpha1.PrometheusServer)
 	return nil
 }
 
-func (o *resource) allResourcesExist(mustExist bool) (bool, error) {
+// ReportStatus updates PrometheusServer status from resource enforcers able to report it
+func (o *resource) ReportStatus(p *v1alpha1.PrometheusServer) error {
+	for _, r := range o.builders {
+		sr, ok := r.(StatusReporter)
+		if !ok {
+			continue
+		}
+		if err := sr.ReportStatus(p); err != nil {
+			return fmt.Errorf("unable to report status on %s error %w", r.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// AnyDrifted checks if any resource has been modified out of the operator
+func (o *resource) AnyDrifted(p *v1alpha1.PrometheusServer) (bool, error) {
+	for _, r := range o.builders {
+		d, ok := r.(DriftDetector)
+		if !ok {
+			continue
+		}
+		drifted, err := d.IsDrifted(p)
+		if err != nil {
+			return false, fmt.Errorf("unable to check drift on %s error %w", r.Name(), err)
+		}
+		if drifted {
+			log.Infof("resource %s drifted from prometheus server on namespace %s name %s", r.Name(), p.Namespace, p.Name)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// UpdateConfig updates config in place from resource enforcers able to update it, returns true if any was updated
+func (o *resource) UpdateConfig(ctx context.Context, p *v1alpha1.PrometheusServer) (bool, error) {
+	var updated bool
+	for _, r := range o.builders {
+		cu, ok := r.(ConfigUpdater)
+		if !ok {
+			continue
+		}
+		u, err := cu.UpdateConfig(ctx, p)
+		if err != nil {
+			return false, fmt.Errorf("unable to update config on %s error %w", r.Name(), err)
+		}
+		if u {
+			log.Infof("resource %s config updated from prometheus server on namespace %s name %s", r.Name(), p.Namespace, p.Name)
+			updated = true
+		}
+	}
+
+	return updated, nil
+}
+
+// Restart restarts workloads from resources able to restart them
+func (o *resource) Restart(ctx context.Context, p *v1alpha1.PrometheusServer, at string) error {
+	for _, r := range o.builders {
+		rs, ok := r.(Restarter)
+		if !ok {
+			continue
+		}
+		if err := rs.Restart(ctx, p, at); err != nil {
+			return fmt.Errorf("unable to restart %s error %w", r.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// AnyFailed returns first failure reason found on resources, empty when none failed
+func (o *resource) AnyFailed(p *v1alpha1.PrometheusServer) (string, error) {
 	for _, r := range o.builders {
+		fd, ok := r.(FailureDetector)
+		if !ok {
+			continue
+		}
+		reason, err := fd.Failure(p)
+		if err != nil {
+			return "", fmt.Errorf("resource %s failure check error %w", r.Name(), err)
+		}
+		if reason != "" {
+			return reason, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Snapshot takes TSDB snapshots from resources able to take them
+func (o *resource) Snapshot(ctx context.Context, p *v1alpha1.PrometheusServer) error {
+	for _, r := range o.builders {
+		sn, ok := r.(Snapshotter)
+		if !ok {
+			continue
+		}
+		if err := sn.Snapshot(ctx, p); err != nil {
+			return fmt.Errorf("unable to snapshot %s error %w", r.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Reload reloads config from resources able to reload it
+func (o *resource) Reload(ctx context.Context, p *v1alpha1.PrometheusServer) error {
+	for _, r := range o.builders {
+		cr, ok := r.(ConfigReloader)
+		if !ok {
+			continue
+		}
+		if err := cr.Reload(ctx, p); err != nil {
+			return fmt.Errorf("unable to reload %s error %w", r.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// RollOut creates rolled out resources on color
+func (o *resource) RollOut(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error {
+	log.Infof("Rolling out %s resources from prometheus server on namespace %s name %s ", color, p.Namespace, p.Name)
+
+	for _, r := range o.builders {
+		ro, ok := r.(RolloutEnforcer)
+		if !ok {
+			continue
+		}
+		if err := ro.EnsureColorCreation(ctx, p, color); err != nil {
+			return fmt.Errorf("unable to ensure %s rollout on %s error %w", color, r.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// RolloutReady checks all rolled out resources on color are ready
+func (o *resource) RolloutReady(ctx context.Context, p *v1alpha1.PrometheusServer, color string) (bool, error) {
+	for _, r := range o.builders {
+		ro, ok := r.(RolloutEnforcer)
+		if !ok {
+			continue
+		}
+		ready, err := ro.IsColorReady(ctx, p, color)
+		if err != nil {
+			return false, fmt.Errorf("resource %s %s rollout check error %w", r.Name(), color, err)
+		}
+		if !ready {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// DeleteRollout removes rolled out resources on color, in the opposite order as rollout
+func (o *resource) DeleteRollout(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error {
+	log.Infof("Delete %s resources from prometheus server on namespace %s name %s ", color, p.Namespace, p.Name)
+
+	for i := len(o.builders) - 1; i >= 0; i-- {
+		ro, ok := o.builders[i].(RolloutEnforcer)
+		if !ok {
+			continue
+		}
+		if err := ro.EnsureColorDeletion(ctx, p, color); err != nil {
+			return fmt.Errorf("unable to ensure %s deletion on %s error %w", color, o.builders[i].Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (o *resource) allResourcesExist(builders []ResourceEnforcer, mustExist bool) (bool, error) {
+	for _, r := range builders {
 		ok, err := r.IsCreated()
 		if err != nil {
 			return false, fmt.Errorf("resource %s creation check error %w", r.Name(), err)
@@ -86,3 +329,15 @@ func (o *resource) allResourcesExist(mustExist bool) (bool, error) {
 
 	return true, nil
 }
+
+// required filters out optional resource enforcers not required by PrometheusServer
+func (o *resource) required(p *v1alpha1.PrometheusServer) []ResourceEnforcer {
+	var res []ResourceEnforcer
+	for _, r := range o.builders {
+		if opt, ok := r.(OptionalEnforcer); ok && !opt.IsRequired(p) {
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
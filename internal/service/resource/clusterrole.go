@@ -73,6 +73,11 @@ func (c *clusterRole) IsCreated() (bool, error) {
 	return true, nil
 }
 
+// IsRequired checks if PrometheusServer is cluster scoped
+func (c *clusterRole) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return rbacScope(obj) == v1alpha1.ClusterScope
+}
+
 // Name returns resource enforcer target name
 func (c *clusterRole) Name() string {
 	return clusterRoleResourceName
@@ -85,30 +90,20 @@ func (c *clusterRole) create(ctx context.Context, obj *v1alpha1.PrometheusServer
 		ObjectMeta: metav1.ObjectMeta{
 			Name: c.name,
 		},
-		Rules: []rbac.PolicyRule{
-			{
+		Rules: append(namespacedRules(),
+			rbac.PolicyRule{
 				APIGroups: []string{""},
 				Resources: []string{
 					"nodes",
 					"nodes/proxy",
-					"services",
-					"endpoints",
-					"pods",
 				},
 				Verbs: []string{"get", "list", "watch"},
 			},
-			{
-				APIGroups: []string{"extensions"},
-				Resources: []string{
-					"ingress",
-				},
-				Verbs: []string{"get", "list", "watch"},
-			},
-			{
+			rbac.PolicyRule{
 				NonResourceURLs: []string{"/metrics"},
 				Verbs:           []string{"get"},
 			},
-		},
+		),
 	}
 
 	_, err := c.client.RbacV1().ClusterRoles().Create(ctx, cm, metav1.CreateOptions{})
@@ -17,7 +17,7 @@ import (
 const clusterRoleBindingResourceName = "clusterrolebindings"
 const clusterRoleBindingName = service2.MonitoringName + "-role-binding"
 const rbacApiGroup = "rbac.authorization.k8s.io"
-const serviceAccount = "ServiceAccount"
+const serviceAccountKind = "ServiceAccount"
 
 type clusterRoleBinding struct {
 	client kubernetes.Interface
@@ -76,6 +76,11 @@ func (c *clusterRoleBinding) IsCreated() (bool, error) {
 	return true, nil
 }
 
+// IsRequired checks if PrometheusServer is cluster scoped
+func (c *clusterRoleBinding) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return rbacScope(obj) == v1alpha1.ClusterScope
+}
+
 // Name returns resource enforcer target name
 func (c *clusterRoleBinding) Name() string {
 	return clusterRoleBindingResourceName
@@ -94,8 +99,8 @@ func (c *clusterRoleBinding) create(ctx context.Context, obj *v1alpha1.Prometheu
 		},
 		Subjects: []rbac.Subject{
 			{
-				Kind:      serviceAccount,
-				Name:      "default",
+				Kind:      serviceAccountKind,
+				Name:      serviceAccountName(obj),
 				Namespace: service2.MonitoringNamespace,
 			},
 		},
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
 )
 
 func TestItCreatesClusterRoleBindingOnCreationRequest(t *testing.T) {
@@ -19,7 +22,7 @@ func TestItCreatesClusterRoleBindingOnCreationRequest(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 
-	pm := &v1alpha1.PrometheusServer{}
+	pm := &v1alpha1.PrometheusServer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
 	if err := svc.EnsureCreation(ctx, pm); err != nil {
 		t.Fatalf("unable to ensure cluster role binding creation, error %v", err)
 	}
@@ -34,6 +37,18 @@ func TestItCreatesClusterRoleBindingOnCreationRequest(t *testing.T) {
 	if expected, got := clusterRoleBindingResourceName, action.GetResource().Resource; expected != got {
 		t.Fatalf("unexpected resource, expected %s got %s", expected, got)
 	}
+
+	v, ok := action.(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", action)
+	}
+	b, ok := v.GetObject().(*rbac.ClusterRoleBinding)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	if expected, got := "prometheus-server-default-foo", b.Subjects[0].Name; expected != got {
+		t.Errorf("subject does not match, expected %s got %s", expected, got)
+	}
 }
 
 func TestItDeletesClusterRoleBindingOnDeletionRequest(t *testing.T) {
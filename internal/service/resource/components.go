@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"gopkg.in/yaml.v2"
+)
+
+// appendComponentJobs adds scrape jobs for deployed components, jobs already defined by name are kept
+func appendComponentJobs(cfg *promconfig.Config, obj *v1alpha1.PrometheusServer) {
+	c := obj.Spec.Components
+	if c == nil {
+		return
+	}
+
+	defined := map[string]bool{}
+	for _, name := range cfg.JobNames() {
+		defined[name] = true
+	}
+
+	if c.NodeExporter != nil && !defined[nodeExporterName] {
+		cfg.Append(scrapeConfigsKey, nodeExporterJob())
+	}
+	if c.KubeStateMetrics != nil && !defined[kubeStateMetricsName] {
+		cfg.Append(scrapeConfigsKey, kubeStateMetricsJob())
+	}
+}
+
+// nodeExporterJob discovers node-exporter pods through its headless Service endpoints
+func nodeExporterJob() yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: "job_name", Value: nodeExporterName},
+		{Key: "kubernetes_sd_configs", Value: []yaml.MapSlice{{
+			{Key: "role", Value: "endpoints"},
+			{Key: "namespaces", Value: yaml.MapSlice{{Key: "names", Value: []string{svc.MonitoringNamespace}}}},
+		}}},
+		{Key: "relabel_configs", Value: []yaml.MapSlice{
+			{
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_endpoints_name"}},
+				{Key: "regex", Value: nodeExporterName},
+				{Key: "action", Value: "keep"},
+			},
+			{
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_node_name"}},
+				{Key: "target_label", Value: "node"},
+			},
+		}},
+	}
+}
+
+// kubeStateMetricsJob scrapes kube-state-metrics Service, its metrics already carry object namespace labels
+func kubeStateMetricsJob() yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: "job_name", Value: kubeStateMetricsName},
+		{Key: "honor_labels", Value: true},
+		{Key: "static_configs", Value: []yaml.MapSlice{
+			{{Key: "targets", Value: []string{
+				fmt.Sprintf("%s.%s.svc:%d", kubeStateMetricsName, kubeStateMetricsNamespace, kubeStateMetricsPort),
+			}}},
+		}},
+	}
+}
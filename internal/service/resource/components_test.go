@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestItAddsComponentScrapeJobsOnDeployedComponents(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Config: "scrape_configs:\n- job_name: base\n",
+			Components: &v1alpha1.ComponentsSpec{
+				NodeExporter:     &v1alpha1.ComponentSpec{},
+				KubeStateMetrics: &v1alpha1.ComponentSpec{},
+			},
+		},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: base
+- job_name: node-exporter
+  kubernetes_sd_configs:
+  - role: endpoints
+    namespaces:
+      names:
+      - monitoring
+  relabel_configs:
+  - source_labels:
+    - __meta_kubernetes_endpoints_name
+    regex: node-exporter
+    action: keep
+  - source_labels:
+    - __meta_kubernetes_pod_node_name
+    target_label: node
+- job_name: kube-state-metrics
+  honor_labels: true
+  static_configs:
+  - targets:
+    - kube-state-metrics.kube-system.svc:8080
+`
+	if !strings.HasPrefix(cf, expected) {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func TestItKeepsUserDefinedComponentScrapeJobs(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Config:     "scrape_configs:\n- job_name: node-exporter\n",
+			Components: &v1alpha1.ComponentsSpec{NodeExporter: &v1alpha1.ComponentSpec{}},
+		},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+	if !strings.HasPrefix(cf, "scrape_configs:\n- job_name: node-exporter\nglobal:") {
+		t.Errorf("unexpected config %s", cf)
+	}
+}
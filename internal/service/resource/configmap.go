@@ -5,10 +5,13 @@ import (
 	"fmt"
 
 	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	crdListers "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/listers/prometheusserver/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	listersV1 "k8s.io/client-go/listers/core/v1"
@@ -21,59 +24,109 @@ const configMapResourceName = "configmaps"
 type configMap struct {
 	client    kubernetes.Interface
 	lister    listersV1.ConfigMapLister
+	servers   crdListers.PrometheusServerLister
+	labels    ExternalLabels
+	vars      TemplateVars
 	namespace string
 	name      string
 }
 
 // NewConfigMap instantiates configmap resource enforcer
-func NewConfigMap(cl kubernetes.Interface, l listersV1.ConfigMapLister) service2.ResourceEnforcer {
+func NewConfigMap(cl kubernetes.Interface, l listersV1.ConfigMapLister, psl crdListers.PrometheusServerLister, el ExternalLabels, vars TemplateVars) service2.ResourceEnforcer {
 	return &configMap{
 		client:    cl,
 		lister:    l,
+		servers:   psl,
+		labels:    el,
+		vars:      vars,
 		namespace: service2.MonitoringNamespace,
 		name:      prometheusConfigMapName,
 	}
 }
 
-// EnsureCreation checks configmap existence, if it's not found it will create it
+// EnsureCreation checks each shard configmap existence on active color, if it's not found it will create it
 func (c *configMap) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
-	_, err := c.lister.ConfigMaps(c.namespace).Get(c.name)
-	if apierrors.IsNotFound(err) {
-		return c.create(ctx, obj)
-	}
+	for shard := int32(0); shard < shards(obj); shard++ {
+		name := shardName(colorName(c.name, activeColor(obj)), shard)
+		_, err := c.lister.ConfigMaps(c.namespace).Get(name)
+		if err == nil {
+			continue
+		}
 
-	if err != nil {
-		return fmt.Errorf("unable to get config map %w", err)
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get config map %s %w", name, err)
+		}
+
+		if err := c.create(ctx, obj, shard); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// EnsureDeletion checks configmap existence, if it's it will delete it
+// EnsureDeletion removes all shard configmaps, first shard one is removed by name even if it's not listed yet
 func (c *configMap) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
-	log.Debugf("removing configmap  %s", c.name)
-	err := c.client.CoreV1().ConfigMaps(c.namespace).Delete(ctx, prometheusConfigMapName, metav1.DeleteOptions{})
-	if apierrors.IsNotFound(err) {
-		return nil
-	}
+	cms, err := c.lister.ConfigMaps(c.namespace).List(shardSelector())
 	if err != nil {
-		return fmt.Errorf("unable to delete configmap, error %w", err)
+		return fmt.Errorf("unable to list configmaps, error %w", err)
+	}
+
+	names := []string{c.name}
+	for _, cm := range cms {
+		if cm.Name != c.name {
+			names = append(names, cm.Name)
+		}
+	}
+
+	for _, name := range names {
+		log.Debugf("removing configmap  %s", name)
+		err := c.client.CoreV1().ConfigMaps(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete configmap %s, error %w", name, err)
+		}
 	}
 	return nil
 }
 
-// IsCreated check if resource exists
+// IsCreated check if any shard configmap exists, they are created all together on creation
 func (c *configMap) IsCreated() (bool, error) {
-	_, err := c.lister.ConfigMaps(c.namespace).Get(c.name)
-	if apierrors.IsNotFound(err) {
-		return false, nil
+	cms, err := c.list("")
+	if err != nil {
+		return false, err
 	}
 
+	return len(cms) > 0, nil
+}
+
+// EnsureColorCreation creates shard configmaps on color, config render result is reported on PrometheusServer
+func (c *configMap) EnsureColorCreation(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error {
+	cp := withColor(obj, color)
+	err := c.EnsureCreation(ctx, cp)
+	obj.Status.Conditions = cp.Status.Conditions
+	return err
+}
+
+// IsColorReady returns true, configmaps are ready once created
+func (c *configMap) IsColorReady(_ context.Context, _ *v1alpha1.PrometheusServer, _ string) (bool, error) {
+	return true, nil
+}
+
+// EnsureColorDeletion removes shard configmaps on color
+func (c *configMap) EnsureColorDeletion(ctx context.Context, _ *v1alpha1.PrometheusServer, color string) error {
+	cms, err := c.list(color)
 	if err != nil {
-		return false, fmt.Errorf("unable to get cofigmap %w", err)
+		return err
 	}
 
-	return true, nil
+	for _, cm := range cms {
+		log.Debugf("removing configmap  %s", cm.Name)
+		err := c.client.CoreV1().ConfigMaps(c.namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete configmap %s, error %w", cm.Name, err)
+		}
+	}
+	return nil
 }
 
 // Name returns resource enforcer target name
@@ -81,18 +134,170 @@ func (c *configMap) Name() string {
 	return configMapResourceName
 }
 
-func (c *configMap) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
-	log.Debugf("creating configmap  %s", c.name)
+// UpdateConfig updates active color shard configmaps in place when they differ from rendered config
+func (c *configMap) UpdateConfig(ctx context.Context, obj *v1alpha1.PrometheusServer) (bool, error) {
+	var updated bool
+	for shard := int32(0); shard < shards(obj); shard++ {
+		cm, err := c.lister.ConfigMaps(c.namespace).Get(shardName(colorName(c.name, activeColor(obj)), shard))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("unable to get config map %w", err)
+		}
+
+		cfg, err := c.prometheusConfig(obj, shard)
+		if err != nil {
+			setConfigRenderedCondition(obj, err)
+			return false, nil
+		}
+
+		if cm.Data[prometheusConfigMapKey] == cfg {
+			continue
+		}
+
+		log.Debugf("updating configmap  %s", cm.Name)
+		cp := cm.DeepCopy()
+		cp.Data = map[string]string{prometheusConfigMapKey: cfg}
+		if _, err := c.client.CoreV1().ConfigMaps(c.namespace).Update(ctx, cp, metav1.UpdateOptions{}); err != nil {
+			return false, fmt.Errorf("unable to update configmap %s, error %w", cm.Name, err)
+		}
+		updated = true
+	}
+
+	setConfigRenderedCondition(obj, nil)
+	if updated {
+		// pending reload waits from last update, as previous updates may not be synced yet
+		meta.RemoveStatusCondition(&obj.Status.Conditions, v1alpha1.ConfigReloaded)
+		meta.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+			Type:               v1alpha1.ConfigReloaded,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.Generation,
+			Reason:             "ReloadPending",
+			Message:            "prometheus config updated, waiting mounted config sync",
+		})
+	}
+	return updated, nil
+}
+
+func (c *configMap) create(ctx context.Context, obj *v1alpha1.PrometheusServer, shard int32) error {
+	name := shardName(colorName(c.name, activeColor(obj)), shard)
+	log.Debugf("creating configmap  %s", name)
+	cfg, err := c.prometheusConfig(obj, shard)
+	if err != nil {
+		setConfigRenderedCondition(obj, err)
+		return err
+	}
+	setConfigRenderedCondition(obj, nil)
+
 	cm := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      c.name,
+			Name:      name,
 			Namespace: c.namespace,
+			Labels:    colorLabels(shard, activeColor(obj)),
 		},
-		Data: map[string]string{prometheusConfigMapKey: obj.Spec.Config},
+		Data: map[string]string{prometheusConfigMapKey: cfg},
 	}
-	_, err := c.client.CoreV1().ConfigMaps(c.namespace).Create(ctx, cm, metav1.CreateOptions{})
+	_, err = c.client.CoreV1().ConfigMaps(c.namespace).Create(ctx, cm, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("unable to create configmap, error %w", err)
 	}
 	return nil
 }
+
+// list returns shard configmaps on color, all colors when it's empty
+func (c *configMap) list(color string) ([]*v1.ConfigMap, error) {
+	selector := shardSelector()
+	if color != "" {
+		selector = colorSelector(color)
+	}
+	cms, err := c.lister.ConfigMaps(c.namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list configmaps %w", err)
+	}
+	if color == v1alpha1.GreenColor {
+		return cms, nil
+	}
+
+	for _, cm := range cms {
+		if cm.Name == c.name {
+			return cms, nil
+		}
+	}
+
+	cm, err := c.lister.ConfigMaps(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return cms, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to get cofigmap %w", err)
+	}
+
+	return append(cms, cm), nil
+}
+
+// prometheusConfig renders PrometheusServer shard config adding operator managed sections
+func (c *configMap) prometheusConfig(obj *v1alpha1.PrometheusServer, shard int32) (string, error) {
+	raw, err := renderConfig(obj, c.vars)
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := promconfig.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.appendConfigFragments(cfg, obj); err != nil {
+		return "", err
+	}
+
+	if err := c.appendFederationJobs(cfg, obj); err != nil {
+		return "", err
+	}
+
+	appendComponentJobs(cfg, obj)
+	appendSelfScrapeJob(cfg, obj)
+
+	if err := validateJobNames(cfg); err != nil {
+		return "", err
+	}
+
+	if err := validateMode(obj, cfg); err != nil {
+		return "", err
+	}
+
+	if err := c.labels.apply(cfg, obj, shard); err != nil {
+		return "", err
+	}
+
+	appendShardRelabelConfigs(cfg, obj, shard)
+	appendRemoteEndpoints(cfg, obj)
+
+	if ns := rbacNamespaces(obj); len(ns) > 0 {
+		if err := cfg.RestrictKubernetesSDNamespaces(ns); err != nil {
+			return "", err
+		}
+	}
+
+	return cfg.Marshal()
+}
+
+// setConfigRenderedCondition reports config rendering result, render error is shown on condition message
+func setConfigRenderedCondition(obj *v1alpha1.PrometheusServer, err error) {
+	c := metav1.Condition{
+		Type:               v1alpha1.ConfigRendered,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.Generation,
+		Reason:             "Rendered",
+		Message:            "prometheus config rendered",
+	}
+	if err != nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason = "RenderError"
+		c.Message = err.Error()
+	}
+	meta.SetStatusCondition(&obj.Status.Conditions, c)
+}
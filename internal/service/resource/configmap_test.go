@@ -2,14 +2,17 @@ package resource
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	k8stest "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestItCreatesConfigMapOnCreationRequest(t *testing.T) {
@@ -17,14 +20,15 @@ func TestItCreatesConfigMapOnCreationRequest(t *testing.T) {
 	sif := informers.NewSharedInformerFactory(clientSet, 0)
 	i := sif.Core().V1().ConfigMaps()
 
-	svc := NewConfigMap(clientSet, i.Lister())
+	svc := NewConfigMap(clientSet, i.Lister(), nil, ExternalLabels{}, nil)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancel()
 
-	version := "v1.0.1"
-	fakeConfig := "fakeConfig"
+	version := "v2.35.0"
+	fakeConfig := "scrape_configs: []\n"
 	pm := &v1alpha1.PrometheusServer{
-		Spec: v1alpha1.PrometheusServerSpec{Version: version, Config: fakeConfig},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec:       v1alpha1.PrometheusServerSpec{Version: version, Config: fakeConfig},
 	}
 	if err := svc.EnsureCreation(ctx, pm); err != nil {
 		t.Fatalf("unable to ensure deployment creation, error %v", err)
@@ -57,8 +61,9 @@ func TestItCreatesConfigMapOnCreationRequest(t *testing.T) {
 		t.Fatal("prometheus config not found in response")
 	}
 
-	if expected, got := fakeConfig, cf; expected != got {
-		t.Fatalf("image version do not match, expected %s got %s", expected, got)
+	expected := "scrape_configs: []\nglobal:\n  external_labels:\n    prometheus_replica: ${POD_NAME}\n    prometheus: default/prometheus\n"
+	if got := cf; expected != got {
+		t.Fatalf("prometheus config do not match, expected %s got %s", expected, got)
 	}
 }
 
@@ -67,7 +72,7 @@ func TestItDeletesConfigMapOnDeletionRequest(t *testing.T) {
 	sif := informers.NewSharedInformerFactory(clientSet, 0)
 	i := sif.Core().V1().ConfigMaps()
 
-	svc := NewConfigMap(clientSet, i.Lister())
+	svc := NewConfigMap(clientSet, i.Lister(), nil, ExternalLabels{}, nil)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancel()
 
@@ -85,3 +90,64 @@ func TestItDeletesConfigMapOnDeletionRequest(t *testing.T) {
 		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
 	}
 }
+
+func TestItFailsCreatingConfigMapWithInvalidConfig(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().ConfigMaps()
+
+	svc := NewConfigMap(clientSet, i.Lister(), nil, ExternalLabels{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1", Config: "global: [foo"},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err == nil {
+		t.Fatal("expected invalid config error")
+	}
+
+	if expected, got := 0, len(clientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+}
+
+func TestItRestrictsServiceDiscoveryNamespacesOnNamespacesRBACScope(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Config: "scrape_configs:\n- job_name: pods\n  kubernetes_sd_configs:\n  - role: pod\n",
+			RBAC:   &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a"}},
+		},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building config, error %v", err)
+	}
+	if !strings.Contains(cf, "namespaces:\n      names:\n      - team-a\n") {
+		t.Errorf("expected service discovery restricted to scope namespaces, got %s", cf)
+	}
+}
+
+func getFakePrometheusServer(namespace, name string) *v1alpha1.PrometheusServer {
+	return &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version: "v2.35.0",
+			Config:  "scrape_configs: []\n",
+		},
+	}
+}
+
+func newIndexer(t *testing.T, objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, o := range objs {
+		if err := indexer.Add(o); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+	return indexer
+}
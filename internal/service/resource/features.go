@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"golang.org/x/mod/semver"
+)
+
+const enableFeatureArg = "--enable-feature="
+
+// featureFlag is a Prometheus feature enabled through --enable-feature from since version until builtin version
+type featureFlag struct {
+	name    string
+	since   string
+	builtin string
+}
+
+// agent mode was released as feature flag on 2.32.0, since 3.0.0 it's enabled by its own flag
+var agentFeature = featureFlag{name: "agent", since: "v2.32.0", builtin: "v3.0.0"}
+
+// expand external labels allows replica external label to take pod name, it's enabled by default since 3.0.0
+var expandExternalLabelsFeature = featureFlag{name: "expand-external-labels", since: "v2.27.0", builtin: "v3.0.0"}
+
+// supported checks if PrometheusServer version has the feature, either as feature flag or builtin
+func (f featureFlag) supported(obj *v1alpha1.PrometheusServer) bool {
+	return semver.Compare(version.Canonical(obj.Spec.Version), f.since) >= 0
+}
+
+// isBuiltin checks if PrometheusServer version has the feature out of feature flags
+func (f featureFlag) isBuiltin(obj *v1alpha1.PrometheusServer) bool {
+	return f.builtin != "" && semver.Compare(version.Canonical(obj.Spec.Version), f.builtin) >= 0
+}
+
+// args returns feature flag arg, versions without the feature or having it builtin get none
+func (f featureFlag) args(obj *v1alpha1.PrometheusServer) []string {
+	if !f.supported(obj) || f.isBuiltin(obj) {
+		return nil
+	}
+	return []string{enableFeatureArg + f.name}
+}
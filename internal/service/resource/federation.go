@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const scrapeConfigsKey = "scrape_configs"
+const federateJobPrefix = "federate-"
+const federateEndpoint = "/federate"
+
+// appendFederationJobs adds a /federate scrape job per selected PrometheusServer, in namespace and name order
+func (c *configMap) appendFederationJobs(cfg *promconfig.Config, obj *v1alpha1.PrometheusServer) error {
+	f := obj.Spec.Federation
+	if f == nil {
+		return nil
+	}
+
+	if len(f.Match) == 0 {
+		return fmt.Errorf("federation requires at least one match selector")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(f.From)
+	if err != nil {
+		return fmt.Errorf("invalid federation selector, error %w", err)
+	}
+
+	servers, err := c.servers.List(selector)
+	if err != nil {
+		return fmt.Errorf("unable to list federated prometheus servers, error %w", err)
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].Namespace != servers[j].Namespace {
+			return servers[i].Namespace < servers[j].Namespace
+		}
+		return servers[i].Name < servers[j].Name
+	})
+
+	for _, ps := range servers {
+		if (ps.Namespace == obj.Namespace && ps.Name == obj.Name) || !ps.DeletionTimestamp.IsZero() {
+			continue
+		}
+		job, err := federateJob(ps, f)
+		if err != nil {
+			return err
+		}
+		cfg.Append(scrapeConfigsKey, job)
+	}
+
+	return nil
+}
+
+// federateJob scrapes federated PrometheusServer through Prometheus Service, scheme, TLS and auth follow its web spec
+func federateJob(ps *v1alpha1.PrometheusServer, f *v1alpha1.FederationSpec) (yaml.MapSlice, error) {
+	var basic *v1alpha1.BasicAuth
+	if webBasicAuth(ps) {
+		if f.BasicAuth == nil {
+			return nil, fmt.Errorf("federated prometheus server %s/%s requires federation basic auth", ps.Namespace, ps.Name)
+		}
+		basic = f.BasicAuth
+	}
+
+	var tls *v1alpha1.TLSConfig
+	if webTLS(ps) {
+		tls = &v1alpha1.TLSConfig{}
+		if f.TLSConfig != nil {
+			tls = f.TLSConfig.DeepCopy()
+		}
+		if tls.ServerName == "" {
+			tls.ServerName = serviceHost()
+		}
+	}
+
+	job := yaml.MapSlice{
+		{Key: "job_name", Value: fmt.Sprintf("%s%s-%s", federateJobPrefix, ps.Namespace, ps.Name)},
+		{Key: "honor_labels", Value: true},
+		{Key: "metrics_path", Value: path.Join(routePrefix(ps), federateEndpoint)},
+		{Key: "params", Value: yaml.MapSlice{{Key: "match[]", Value: f.Match}}},
+		{Key: "scheme", Value: strings.ToLower(string(webScheme(ps)))},
+	}
+	job = appendAuth(job, basic, nil, tls)
+	return append(job, yaml.MapItem{Key: "static_configs", Value: []yaml.MapSlice{
+		{{Key: "targets", Value: []string{fmt.Sprintf("%s:%d", serviceHost(), servicePort(ps))}}},
+	}}), nil
+}
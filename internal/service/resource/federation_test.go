@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	crdFake "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/clientset/versioned/fake"
+	crdinformers "github.com/marcosQuesada/prometheus-operator/pkg/crd/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestItAddsFederationJobsFromSelectedPrometheusServers(t *testing.T) {
+	selected := map[string]string{"federated": "true"}
+	global := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "global", Labels: selected},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Config: "scrape_configs: []\n",
+			Federation: &v1alpha1.FederationSpec{
+				From:      &metav1.LabelSelector{MatchLabels: selected},
+				Match:     []string{`{job="kubernetes-pods"}`},
+				BasicAuth: &v1alpha1.BasicAuth{Username: "federate", Password: v1alpha1.SecretKeyRef{Name: "federate-auth", Key: "password"}},
+				TLSConfig: &v1alpha1.TLSConfig{CA: &v1alpha1.SecretKeyRef{Name: "federate-tls", Key: "ca.crt"}},
+			},
+		},
+	}
+	servers := []*v1alpha1.PrometheusServer{
+		global,
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "prometheus", Labels: selected}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "prometheus", Labels: selected},
+			Spec: v1alpha1.PrometheusServerSpec{
+				Service: &v1alpha1.ServiceSpec{Port: 9090},
+				Ingress: &v1alpha1.IngressSpec{Host: "prometheus.example.com", Path: "/team-a"},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "prometheus"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-d", Name: "prometheus", Labels: selected},
+			Spec: v1alpha1.PrometheusServerSpec{
+				Web: &v1alpha1.WebSpec{
+					TLSSecretRef:   &corev1.LocalObjectReference{Name: "prometheus-tls"},
+					BasicAuthUsers: &corev1.LocalObjectReference{Name: "prometheus-users"},
+				},
+			},
+		},
+	}
+
+	i := crdinformers.NewSharedInformerFactory(crdFake.NewSimpleClientset(), 0).K8slab().V1alpha1().PrometheusServers()
+	for _, ps := range servers {
+		if err := i.Informer().GetIndexer().Add(ps); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	cfg, err := promconfig.Parse(global.Spec.Config)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+	c := &configMap{servers: i.Lister()}
+	if err := c.appendFederationJobs(cfg, global); err != nil {
+		t.Fatalf("unexpected error adding federation jobs %v", err)
+	}
+	cf, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: federate-team-a-prometheus
+  honor_labels: true
+  metrics_path: /team-a/federate
+  params:
+    match[]:
+    - '{job="kubernetes-pods"}'
+  scheme: http
+  static_configs:
+  - targets:
+    - prometheus-server-service.monitoring.svc:9090
+- job_name: federate-team-b-prometheus
+  honor_labels: true
+  metrics_path: /federate
+  params:
+    match[]:
+    - '{job="kubernetes-pods"}'
+  scheme: http
+  static_configs:
+  - targets:
+    - prometheus-server-service.monitoring.svc:8080
+- job_name: federate-team-d-prometheus
+  honor_labels: true
+  metrics_path: /federate
+  params:
+    match[]:
+    - '{job="kubernetes-pods"}'
+  scheme: https
+  basic_auth:
+    username: federate
+    password_file: /etc/prometheus-secrets/federate-auth/password
+  tls_config:
+    ca_file: /etc/prometheus-secrets/federate-tls/ca.crt
+    server_name: prometheus-server-service.monitoring.svc
+  static_configs:
+  - targets:
+    - prometheus-server-service.monitoring.svc:8080
+`
+	if expected != cf {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func TestItRejectsFederationWithoutMatchSelectors(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Federation: &v1alpha1.FederationSpec{From: &metav1.LabelSelector{}},
+		},
+	}
+
+	if err := (&configMap{}).appendFederationJobs(&promconfig.Config{}, pm); err == nil {
+		t.Fatal("expected match selectors error")
+	}
+}
+
+func TestItRejectsFederationWithoutBasicAuthOnProtectedServers(t *testing.T) {
+	ps := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "prometheus"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Web: &v1alpha1.WebSpec{BasicAuthUsers: &corev1.LocalObjectReference{Name: "prometheus-users"}},
+		},
+	}
+
+	if _, err := federateJob(ps, &v1alpha1.FederationSpec{Match: []string{"up"}}); err == nil {
+		t.Fatal("expected basic auth required error")
+	}
+}
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fragmentSections maps config sections allowed on config fragments to the field their entries are merged by
+var fragmentSections = map[string]string{
+	scrapeConfigsKey: "job_name",
+	"rule_files":     "",
+	"remote_write":   "url",
+}
+
+// appendConfigFragments merges selected ConfigMap fragments into config, in ConfigMap name and data key order
+func (c *configMap) appendConfigFragments(cfg *promconfig.Config, obj *v1alpha1.PrometheusServer) error {
+	if obj.Spec.ConfigFragments == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(obj.Spec.ConfigFragments)
+	if err != nil {
+		return fmt.Errorf("invalid config fragments selector, error %w", err)
+	}
+
+	cms, err := c.lister.ConfigMaps(obj.Namespace).List(selector)
+	if err != nil {
+		return fmt.Errorf("unable to list config fragments, error %w", err)
+	}
+	sort.Slice(cms, func(i, j int) bool { return cms[i].Name < cms[j].Name })
+
+	for _, cm := range cms {
+		keys := make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			f, err := promconfig.Parse(cm.Data[k])
+			if err != nil {
+				return fmt.Errorf("invalid config fragment %s key %s, error %w", cm.Name, k, err)
+			}
+			for _, section := range f.Keys() {
+				field, ok := fragmentSections[section]
+				if !ok {
+					return fmt.Errorf("config fragment %s key %s section %s not allowed", cm.Name, k, section)
+				}
+				cfg.Merge(section, field, f.Values(section)...)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateJobNames rejects duplicated scrape job names, as Prometheus does
+func validateJobNames(cfg *promconfig.Config) error {
+	seen := map[string]bool{}
+	for _, name := range cfg.JobNames() {
+		if seen[name] {
+			return fmt.Errorf("duplicated scrape job name %s", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
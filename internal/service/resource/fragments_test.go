@@ -0,0 +1,154 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+var fragmentLabels = map[string]string{"prometheus": "fragment"}
+
+func TestItMergesConfigFragmentsInNameOrder(t *testing.T) {
+	c := NewConfigMap(nil, listersV1.NewConfigMapLister(newIndexer(t,
+		fragment("team-b", map[string]string{"jobs.yml": "scrape_configs:\n- job_name: b\n"}),
+		fragment("team-a", map[string]string{
+			"rules.yml": "rule_files:\n- /etc/rules/a.yml\n",
+			"jobs.yml":  "scrape_configs:\n- job_name: a\nremote_write:\n- url: http://a\n",
+		}),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}, Data: map[string]string{"jobs.yml": "scrape_configs:\n- job_name: other\n"}},
+	)), nil, ExternalLabels{}, nil).(*configMap)
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.ConfigFragments = &metav1.LabelSelector{MatchLabels: fragmentLabels}
+	pm.Spec.Config = "scrape_configs:\n- job_name: base\n"
+	cf, err := c.prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: base
+- job_name: a
+- job_name: b
+remote_write:
+- url: http://a
+rule_files:
+- /etc/rules/a.yml
+global:
+  external_labels:
+    prometheus_replica: ${POD_NAME}
+    prometheus: default/prometheus
+`
+	if expected != cf {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func TestItMergesConfigFragmentEntriesByKey(t *testing.T) {
+	c := NewConfigMap(nil, listersV1.NewConfigMapLister(newIndexer(t,
+		fragment("team-a", map[string]string{"jobs.yml": "scrape_configs:\n- job_name: base\n  scrape_interval: 10s\nremote_write:\n- url: http://a\n  name: a\n"}),
+		fragment("team-b", map[string]string{"jobs.yml": "remote_write:\n- url: http://a\n  name: b\n"}),
+	)), nil, ExternalLabels{}, nil).(*configMap)
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.ConfigFragments = &metav1.LabelSelector{MatchLabels: fragmentLabels}
+	pm.Spec.Config = "scrape_configs:\n- job_name: base\n"
+	cf, err := c.prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: base
+  scrape_interval: 10s
+remote_write:
+- url: http://a
+  name: b
+global:
+  external_labels:
+    prometheus_replica: ${POD_NAME}
+    prometheus: default/prometheus
+`
+	if expected != cf {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func TestItRejectsNotAllowedConfigFragmentSections(t *testing.T) {
+	indexer := newIndexer(t, fragment("team-a", map[string]string{"global.yml": "global:\n  scrape_interval: 5s\n"}))
+	c := NewConfigMap(nil, listersV1.NewConfigMapLister(indexer), nil, ExternalLabels{}, nil).(*configMap)
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.ConfigFragments = &metav1.LabelSelector{MatchLabels: fragmentLabels}
+	if _, err := c.prometheusConfig(pm, 0); err == nil {
+		t.Fatal("expected not allowed section error")
+	}
+}
+
+func TestItUpdatesConfigMapInPlaceOnConfigFragmentChange(t *testing.T) {
+	indexer := newIndexer(t, fragment("team-a", map[string]string{"jobs.yml": "scrape_configs:\n- job_name: a\n"}))
+	c := NewConfigMap(nil, listersV1.NewConfigMapLister(indexer), nil, ExternalLabels{}, nil).(*configMap)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.ConfigFragments = &metav1.LabelSelector{MatchLabels: fragmentLabels}
+
+	cf, err := c.prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: prometheusConfigMapName, Namespace: service2.MonitoringNamespace},
+		Data:       map[string]string{prometheusConfigMapKey: cf},
+	}
+	if err := indexer.Add(live); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+	cl := fake.NewSimpleClientset(live)
+	c.client = cl
+
+	updated, err := c.UpdateConfig(context.Background(), pm)
+	if err != nil {
+		t.Fatalf("unexpected error updating config %v", err)
+	}
+	if updated {
+		t.Fatal("unexpected update on unchanged fragments")
+	}
+
+	if err := indexer.Update(fragment("team-a", map[string]string{"jobs.yml": "scrape_configs:\n- job_name: b\n"})); err != nil {
+		t.Fatalf("unable to update entry on indexer %v", err)
+	}
+	updated, err = c.UpdateConfig(context.Background(), pm)
+	if err != nil {
+		t.Fatalf("unexpected error updating config %v", err)
+	}
+	if !updated {
+		t.Fatal("expected update on changed fragment")
+	}
+
+	cm, err := cl.CoreV1().ConfigMaps(service2.MonitoringNamespace).Get(context.Background(), prometheusConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting configmap %v", err)
+	}
+	if !strings.Contains(cm.Data[prometheusConfigMapKey], "job_name: b") {
+		t.Errorf("expected updated config, got %s", cm.Data[prometheusConfigMapKey])
+	}
+
+	cond := meta.FindStatusCondition(pm.Status.Conditions, v1alpha1.ConfigReloaded)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ReloadPending" {
+		t.Errorf("expected pending config reload condition, got %v", cond)
+	}
+}
+
+func fragment(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: fragmentLabels},
+		Data:       data,
+	}
+}
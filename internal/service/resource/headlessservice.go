@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const prometheusHeadlessServiceName = svc.MonitoringName + "-headless"
+const headlessServiceResourceName = "headless-services"
+
+type headlessService struct {
+	client    kubernetes.Interface
+	lister    listersV1.ServiceLister
+	namespace string
+	name      string
+}
+
+// NewHeadlessService instantiates prometheus headless service resource enforcer, it gives stable network identity to statefulset pods
+func NewHeadlessService(cl kubernetes.Interface, l listersV1.ServiceLister) svc.ResourceEnforcer {
+	return &headlessService{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusHeadlessServiceName,
+	}
+}
+
+// EnsureCreation checks headless service existence, if it's not found it will create it
+func (c *headlessService) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.Services(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get headless service %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion checks headless service existence, if it's it will delete it
+func (c *headlessService) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing headless service  %s", c.name)
+	err := c.client.CoreV1().Services(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to delete headless service, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated check if resource exists
+func (c *headlessService) IsCreated() (bool, error) {
+	_, err := c.lister.Services(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get headless service %w", err)
+	}
+
+	return true, nil
+}
+
+// Name returns resource enforcer target name
+func (c *headlessService) Name() string {
+	return headlessServiceResourceName
+}
+
+func (c *headlessService) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating headless service  %s", c.name)
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:     "http",
+					Port:     prometheusHttpPort,
+					Protocol: corev1.ProtocolTCP,
+				},
+			},
+			Selector: map[string]string{"app": svc.MonitoringName},
+		},
+	}
+	_, err := c.client.CoreV1().Services(c.namespace).Create(ctx, s, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create headless service, error %w", err)
+	}
+	return nil
+}
+
+// headlessServiceHost returns headless Service DNS name
+func headlessServiceHost() string {
+	return fmt.Sprintf("%s.%s.svc", prometheusHeadlessServiceName, svc.MonitoringNamespace)
+}
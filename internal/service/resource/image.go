@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultImageRepository is the official Prometheus image repository
+const DefaultImageRepository = "prom/prometheus"
+
+// Image defines operator level Prometheus image settings, PrometheusServer image spec overrides them
+type Image struct {
+	Repository  string
+	PullPolicy  corev1.PullPolicy
+	PullSecrets []string
+}
+
+// name builds Prometheus image reference, digest pins it when defined, version tag is used otherwise
+func (i Image) name(obj *v1alpha1.PrometheusServer) string {
+	repository := i.Repository
+	if repository == "" {
+		repository = DefaultImageRepository
+	}
+	if s := obj.Spec.Image; s != nil && s.Repository != "" {
+		repository = s.Repository
+	}
+
+	if s := obj.Spec.Image; s != nil && s.Digest != "" {
+		return fmt.Sprintf("%s@%s", repository, s.Digest)
+	}
+
+	return fmt.Sprintf("%s:%s", repository, obj.Spec.Version)
+}
+
+func (i Image) pullPolicy(obj *v1alpha1.PrometheusServer) corev1.PullPolicy {
+	if s := obj.Spec.Image; s != nil && s.PullPolicy != "" {
+		return s.PullPolicy
+	}
+	return i.PullPolicy
+}
+
+// pullSecrets from PrometheusServer replace operator level ones
+func (i Image) pullSecrets(obj *v1alpha1.PrometheusServer) []corev1.LocalObjectReference {
+	if s := obj.Spec.Image; s != nil && len(s.PullSecrets) > 0 {
+		return s.PullSecrets
+	}
+
+	var res []corev1.LocalObjectReference
+	for _, name := range i.PullSecrets {
+		res = append(res, corev1.LocalObjectReference{Name: name})
+	}
+	return res
+}
@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/networking/v1"
+)
+
+const prometheusIngressName = svc.MonitoringName + "-ingress"
+const ingressResourceName = "ingresses"
+
+type ingress struct {
+	client    kubernetes.Interface
+	lister    listersV1.IngressLister
+	namespace string
+	name      string
+}
+
+// NewIngress instantiates prometheus ingress resource enforcer, ingress is only created when PrometheusServer defines it
+func NewIngress(cl kubernetes.Interface, l listersV1.IngressLister) svc.ResourceEnforcer {
+	return &ingress{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusIngressName,
+	}
+}
+
+// EnsureCreation checks ingress existence, if it's not found it will create it
+func (c *ingress) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.Ingresses(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get ingress %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion checks ingress existence, if it's it will delete it
+func (c *ingress) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing ingress  %s", c.name)
+	err := c.client.NetworkingV1().Ingresses(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to delete ingress, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated check if resource exists
+func (c *ingress) IsCreated() (bool, error) {
+	_, err := c.lister.Ingresses(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get ingress %w", err)
+	}
+
+	return true, nil
+}
+
+// IsRequired checks if PrometheusServer defines ingress
+func (c *ingress) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Ingress != nil
+}
+
+// Name returns resource enforcer target name
+func (c *ingress) Name() string {
+	return ingressResourceName
+}
+
+func (c *ingress) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating ingress  %s", c.name)
+	spec := obj.Spec.Ingress
+	pathType := networkingv1.PathTypePrefix
+	i := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.name,
+			Namespace:   c.namespace,
+			Annotations: spec.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     routePrefix(obj),
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: prometheusServiceName,
+											Port: networkingv1.ServiceBackendPort{Number: servicePort(obj)},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if spec.TLSSecretName != "" {
+		i.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+
+	_, err := c.client.NetworkingV1().Ingresses(c.namespace).Create(ctx, i, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create ingress, error %w", err)
+	}
+	return nil
+}
+
+// routePrefix builds Prometheus route prefix from ingress path
+func routePrefix(obj *v1alpha1.PrometheusServer) string {
+	if obj.Spec.Ingress == nil {
+		return "/"
+	}
+	return path.Join("/", obj.Spec.Ingress.Path)
+}
+
+// externalURL builds Prometheus external url from ingress, it's empty when no ingress is defined
+func externalURL(obj *v1alpha1.PrometheusServer) string {
+	spec := obj.Spec.Ingress
+	if spec == nil {
+		return ""
+	}
+
+	scheme := "http"
+	if spec.TLSSecretName != "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, spec.Host, routePrefix(obj))
+}
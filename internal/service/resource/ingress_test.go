@@ -0,0 +1,99 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesIngressOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Networking().V1().Ingresses()
+
+	ing := NewIngress(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Ingress: &v1alpha1.IngressSpec{Host: "prometheus.example.com", Path: "prometheus", TLSSecretName: "prometheus-tls"},
+		},
+	}
+	if err := ing.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure ingress creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	v, ok := clActions[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	in, ok := v.GetObject().(*networkingv1.Ingress)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	if expected, got := "/prometheus", in.Spec.Rules[0].HTTP.Paths[0].Path; expected != got {
+		t.Errorf("path does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := prometheusServiceName, in.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; expected != got {
+		t.Errorf("backend service does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, len(in.Spec.TLS); expected != got {
+		t.Fatalf("tls entries do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "https://prometheus.example.com/prometheus", externalURL(pm); expected != got {
+		t.Errorf("external url does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRequiresIngressOnlyWhenDefined(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Networking().V1().Ingresses()
+
+	ing := NewIngress(clientSet, i.Lister()).(*ingress)
+	if ing.IsRequired(&v1alpha1.PrometheusServer{}) {
+		t.Error("ingress not expected as required")
+	}
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Ingress: &v1alpha1.IngressSpec{Host: "prometheus.example.com"}},
+	}
+	if !ing.IsRequired(pm) {
+		t.Error("ingress expected as required")
+	}
+}
+
+func TestItRemovesIngressOnDeletionRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Networking().V1().Ingresses()
+
+	ing := NewIngress(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := ing.EnsureDeletion(ctx, &v1alpha1.PrometheusServer{}); err != nil {
+		t.Fatalf("unable to ensure ingress deletion, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	if expected, got := "delete", clActions[0].GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,220 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/apps/v1"
+)
+
+const kubeStateMetricsName = "kube-state-metrics"
+const kubeStateMetricsResourceName = "kube-state-metrics"
+const kubeStateMetricsNamespace = "kube-system"
+const kubeStateMetricsDefaultImage = "k8s.gcr.io/kube-state-metrics/kube-state-metrics:v2.4.2"
+const kubeStateMetricsPort = 8080
+
+type kubeStateMetrics struct {
+	client    kubernetes.Interface
+	lister    listersV1.DeploymentLister
+	namespace string
+	name      string
+}
+
+// NewKubeStateMetrics instantiates kube-state-metrics resource enforcer
+func NewKubeStateMetrics(cl kubernetes.Interface, l listersV1.DeploymentLister) svc.ResourceEnforcer {
+	return &kubeStateMetrics{
+		client:    cl,
+		lister:    l,
+		namespace: kubeStateMetricsNamespace,
+		name:      kubeStateMetricsName,
+	}
+}
+
+// EnsureCreation checks kube-state-metrics deployment existence, if it's not found it will create it
+func (c *kubeStateMetrics) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.Deployments(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get kube-state-metrics deployment %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion removes kube-state-metrics deployment, service and RBAC
+func (c *kubeStateMetrics) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing kube-state-metrics %s", c.name)
+	deletions := []struct {
+		kind   string
+		delete func() error
+	}{
+		{"deployment", func() error {
+			return c.client.AppsV1().Deployments(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+		}},
+		{"service", func() error {
+			return c.client.CoreV1().Services(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+		}},
+		{"cluster role binding", func() error {
+			return c.client.RbacV1().ClusterRoleBindings().Delete(ctx, c.name, metav1.DeleteOptions{})
+		}},
+		{"cluster role", func() error {
+			return c.client.RbacV1().ClusterRoles().Delete(ctx, c.name, metav1.DeleteOptions{})
+		}},
+		{"service account", func() error {
+			return c.client.CoreV1().ServiceAccounts(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+		}},
+	}
+	for _, d := range deletions {
+		if err := d.delete(); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete kube-state-metrics %s, error %w", d.kind, err)
+		}
+	}
+	return nil
+}
+
+// IsCreated checks kube-state-metrics deployment exists and its replicas are ready
+func (c *kubeStateMetrics) IsCreated() (bool, error) {
+	d, err := c.lister.Deployments(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get kube-state-metrics deployment %w", err)
+	}
+
+	return d.Spec.Replicas != nil && d.Status.ReadyReplicas >= *d.Spec.Replicas, nil
+}
+
+// IsRequired checks if PrometheusServer components define kube-state-metrics
+func (c *kubeStateMetrics) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Components != nil && obj.Spec.Components.KubeStateMetrics != nil
+}
+
+// Name returns resource enforcer target name
+func (c *kubeStateMetrics) Name() string {
+	return kubeStateMetricsResourceName
+}
+
+// create builds RBAC first, then kube-state-metrics Deployment and Service, existing ones are kept
+func (c *kubeStateMetrics) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating kube-state-metrics %s", c.name)
+	meta := metav1.ObjectMeta{
+		Name:      c.name,
+		Namespace: c.namespace,
+		Labels:    map[string]string{"app": c.name},
+	}
+
+	_, err := c.client.CoreV1().ServiceAccounts(c.namespace).Create(ctx, &corev1.ServiceAccount{ObjectMeta: meta}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create kube-state-metrics service account, error %w", err)
+	}
+
+	cr := &rbac.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: c.name, Labels: meta.Labels},
+		Rules:      kubeStateMetricsRules(),
+	}
+	_, err = c.client.RbacV1().ClusterRoles().Create(ctx, cr, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create kube-state-metrics cluster role, error %w", err)
+	}
+
+	crb := &rbac.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: c.name, Labels: meta.Labels},
+		RoleRef: rbac.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     c.name,
+		},
+		Subjects: []rbac.Subject{
+			{Kind: "ServiceAccount", Name: c.name, Namespace: c.namespace},
+		},
+	}
+	_, err = c.client.RbacV1().ClusterRoleBindings().Create(ctx, crb, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create kube-state-metrics cluster role binding, error %w", err)
+	}
+
+	s := &corev1.Service{
+		ObjectMeta: meta,
+		Spec: corev1.ServiceSpec{
+			Selector: meta.Labels,
+			Ports:    []corev1.ServicePort{{Name: "http-metrics", Port: kubeStateMetricsPort}},
+		},
+	}
+	_, err = c.client.CoreV1().Services(c.namespace).Create(ctx, s, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create kube-state-metrics service, error %w", err)
+	}
+
+	spec := obj.Spec.Components.KubeStateMetrics
+	replicas := int32(1)
+	nonRoot := true
+	user := int64(65534)
+	d := &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: meta.Labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: c.name,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: &nonRoot,
+						RunAsUser:    &user,
+					},
+					Containers: []corev1.Container{
+						{
+							Name:      c.name,
+							Image:     componentImage(spec, kubeStateMetricsDefaultImage),
+							Args:      []string{fmt.Sprintf("--port=%d", kubeStateMetricsPort)},
+							Ports:     []corev1.ContainerPort{{Name: "http-metrics", ContainerPort: kubeStateMetricsPort}},
+							Resources: spec.Resources,
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := c.client.AppsV1().Deployments(c.namespace).Create(ctx, d, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("unable to create kube-state-metrics deployment, error %w", err)
+	}
+	return nil
+}
+
+// kubeStateMetricsRules grants read access to resources exposed by kube-state-metrics default collectors
+func kubeStateMetricsRules() []rbac.PolicyRule {
+	verbs := []string{"list", "watch"}
+	return []rbac.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{
+				"configmaps", "secrets", "nodes", "pods", "services", "resourcequotas", "replicationcontrollers",
+				"limitranges", "persistentvolumeclaims", "persistentvolumes", "namespaces", "endpoints",
+			},
+			Verbs: verbs,
+		},
+		{APIGroups: []string{"apps"}, Resources: []string{"statefulsets", "daemonsets", "deployments", "replicasets"}, Verbs: verbs},
+		{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: verbs},
+		{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: verbs},
+		{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: verbs},
+		{APIGroups: []string{"certificates.k8s.io"}, Resources: []string{"certificatesigningrequests"}, Verbs: verbs},
+		{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses", "volumeattachments"}, Verbs: verbs},
+		{APIGroups: []string{"admissionregistration.k8s.io"}, Resources: []string{"mutatingwebhookconfigurations", "validatingwebhookconfigurations"}, Verbs: verbs},
+		{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies", "ingresses"}, Verbs: verbs},
+		{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: verbs},
+	}
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesKubeStateMetricsWithItsRBACOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	ksm := NewKubeStateMetrics(clientSet, sif.Apps().V1().Deployments().Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Components: &v1alpha1.ComponentsSpec{KubeStateMetrics: &v1alpha1.ComponentSpec{}},
+		},
+	}
+	if err := ksm.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure kube-state-metrics creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	expected := []string{"serviceaccounts", "clusterroles", "clusterrolebindings", "services", "deployments"}
+	if len(expected) != len(clActions) {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", len(expected), len(clActions))
+	}
+	for i, a := range clActions {
+		if a.GetVerb() != "create" || a.GetResource().Resource != expected[i] {
+			t.Errorf("unexpected action %s %s", a.GetVerb(), a.GetResource().Resource)
+		}
+	}
+
+	d := clActions[4].(k8stest.CreateAction).GetObject().(*appsv1.Deployment)
+	if expected, got := kubeStateMetricsNamespace, d.Namespace; expected != got {
+		t.Errorf("namespace does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := kubeStateMetricsDefaultImage, d.Spec.Template.Spec.Containers[0].Image; expected != got {
+		t.Errorf("image does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := kubeStateMetricsName, d.Spec.Template.Spec.ServiceAccountName; expected != got {
+		t.Errorf("service account does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItDeletesKubeStateMetricsIgnoringMissingResources(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	ksm := NewKubeStateMetrics(clientSet, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := ksm.EnsureDeletion(ctx, &v1alpha1.PrometheusServer{}); err != nil {
+		t.Fatalf("unexpected error deleting kube-state-metrics %v", err)
+	}
+	if expected, got := 5, len(clientSet.Actions()); expected != got {
+		t.Errorf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+}
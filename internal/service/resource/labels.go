@@ -0,0 +1,59 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+)
+
+const (
+	// OverrideLabelPolicy replaces user defined external labels managed by the operator
+	OverrideLabelPolicy = "override"
+	// RejectLabelPolicy refuses PrometheusServer configs defining external labels managed by the operator
+	RejectLabelPolicy = "reject"
+)
+
+const replicaExternalLabel = "prometheus_replica"
+const shardExternalLabel = "prometheus_shard"
+const prometheusExternalLabel = "prometheus"
+const clusterExternalLabel = "cluster"
+
+// ExternalLabels defines operator level external labels settings
+type ExternalLabels struct {
+	Cluster string
+	Policy  string
+}
+
+type externalLabel struct {
+	name  string
+	value string
+}
+
+// apply sets operator managed external labels on shard config, user defined ones are overridden or rejected by policy
+func (e ExternalLabels) apply(c *promconfig.Config, obj *v1alpha1.PrometheusServer, shard int32) error {
+	for _, l := range e.managed(obj, shard) {
+		if e.Policy == RejectLabelPolicy && c.HasExternalLabel(l.name) {
+			return fmt.Errorf("external label %s is managed by the operator", l.name)
+		}
+		c.SetExternalLabel(l.name, l.value)
+	}
+	return nil
+}
+
+// managed returns operator managed external labels, shard label is only added to sharded PrometheusServers
+func (e ExternalLabels) managed(obj *v1alpha1.PrometheusServer, shard int32) []externalLabel {
+	var res []externalLabel
+	if expandExternalLabelsFeature.supported(obj) {
+		res = append(res, externalLabel{name: replicaExternalLabel, value: fmt.Sprintf("${%s}", podNameEnv)})
+	}
+	if shards(obj) > 1 {
+		res = append(res, externalLabel{name: shardExternalLabel, value: strconv.Itoa(int(shard))})
+	}
+	res = append(res, externalLabel{name: prometheusExternalLabel, value: fmt.Sprintf("%s/%s", obj.Namespace, obj.Name)})
+	if e.Cluster != "" {
+		res = append(res, externalLabel{name: clusterExternalLabel, value: e.Cluster})
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestItOverridesUserDefinedManagedExternalLabels(t *testing.T) {
+	c, err := promconfig.Parse("global:\n  external_labels:\n    cluster: foo\n    region: eu\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec:       v1alpha1.PrometheusServerSpec{Version: "v2.35.0"},
+	}
+	el := ExternalLabels{Cluster: "production", Policy: OverrideLabelPolicy}
+	if err := el.apply(c, pm, 0); err != nil {
+		t.Fatalf("unexpected error applying external labels %v", err)
+	}
+
+	res, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling config %v", err)
+	}
+
+	expected := `global:
+  external_labels:
+    cluster: production
+    region: eu
+    prometheus_replica: ${POD_NAME}
+    prometheus: default/prometheus
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItRejectsUserDefinedManagedExternalLabelsWithRejectPolicy(t *testing.T) {
+	c, err := promconfig.Parse("global:\n  external_labels:\n    cluster: foo\n")
+	if err != nil {
+		t.Fatalf("unexpected error parsing config %v", err)
+	}
+
+	pm := &v1alpha1.PrometheusServer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"}}
+	el := ExternalLabels{Cluster: "production", Policy: RejectLabelPolicy}
+	if err := el.apply(c, pm, 0); err == nil {
+		t.Fatal("expected managed external label error")
+	}
+}
+
+func TestItSkipsClusterExternalLabelWithoutClusterName(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"}}
+	for _, l := range (ExternalLabels{}).managed(pm, 0) {
+		if l.name == clusterExternalLabel {
+			t.Errorf("unexpected cluster external label %s", l.value)
+		}
+	}
+}
+
+func TestItOmitsReplicaExternalLabelWithoutExpandExternalLabelsFeature(t *testing.T) {
+	for version, expected := range map[string]bool{"v2.26.0": false, "v2.27.0": true, "v3.0.0": true} {
+		pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: version}}
+		got := false
+		for _, l := range (ExternalLabels{}).managed(pm, 0) {
+			if l.name == replicaExternalLabel {
+				got = true
+			}
+		}
+		if expected != got {
+			t.Errorf("version %s replica external label does not match, expected %t got %t", version, expected, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"golang.org/x/mod/semver"
+)
+
+const agentArg = "--agent"
+
+// agent mode does not evaluate rules nor sends alerts
+var agentUnsupportedSections = []string{"rule_files", "alerting"}
+
+func isAgent(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Mode == v1alpha1.AgentMode
+}
+
+// validateMode checks PrometheusServer version and config support its mode
+func validateMode(obj *v1alpha1.PrometheusServer, c *promconfig.Config) error {
+	if !isAgent(obj) {
+		return nil
+	}
+
+	if !semver.IsValid(version.Canonical(obj.Spec.Version)) {
+		return fmt.Errorf("agent mode requires a semantic version, got %s", obj.Spec.Version)
+	}
+	if !agentFeature.supported(obj) {
+		return fmt.Errorf("agent mode requires version %s or newer, got %s", agentFeature.since, obj.Spec.Version)
+	}
+
+	for _, section := range agentUnsupportedSections {
+		if c.Has(section) {
+			return fmt.Errorf("agent mode does not support %s config section", section)
+		}
+	}
+	return nil
+}
+
+// agentModeArg enables agent mode depending on Prometheus version
+func agentModeArg(obj *v1alpha1.PrometheusServer) string {
+	if agentFeature.isBuiltin(obj) {
+		return agentArg
+	}
+	return enableFeatureArg + agentFeature.name
+}
@@ -0,0 +1,112 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestItRunsAgentModeWithoutLocalStorage(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version:   "v2.35.0",
+			Mode:      v1alpha1.AgentMode,
+			Retention: "15d",
+			Storage:   &v1alpha1.StorageSpec{Size: resource.MustParse("10Gi")},
+		},
+	}
+
+	s, err := (&statefulSet{}).build(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building statefulset %v", err)
+	}
+	c := s.Spec.Template.Spec.Containers[0]
+	if !contains(c.Args, "--enable-feature=agent") {
+		t.Errorf("expected agent feature arg not found on %v", c.Args)
+	}
+	for _, arg := range []string{prometheusDbPathArg, "--storage.tsdb.retention.time=15d"} {
+		if contains(c.Args, arg) {
+			t.Errorf("unexpected arg %s on agent mode", arg)
+		}
+	}
+	if expected, got := 0, len(s.Spec.VolumeClaimTemplates); expected != got {
+		t.Errorf("claim templates do not match, expected %d got %d", expected, got)
+	}
+	for _, vol := range s.Spec.Template.Spec.Volumes {
+		if vol.Name == prometheusStorageVolumeName {
+			t.Error("unexpected storage volume on agent mode")
+		}
+	}
+	for _, m := range c.VolumeMounts {
+		if m.Name == prometheusStorageVolumeName {
+			t.Error("unexpected storage volume mount on agent mode")
+		}
+	}
+}
+
+func TestItEnablesAgentModeFlagFromVersion(t *testing.T) {
+	for version, expected := range map[string]string{
+		"v2.32.0": "--enable-feature=agent",
+		"2.40.1":  "--enable-feature=agent",
+		"v3.0.0":  agentArg,
+		"v3.1.0":  agentArg,
+	} {
+		pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: version, Mode: v1alpha1.AgentMode}}
+		if got := agentModeArg(pm); expected != got {
+			t.Errorf("agent arg does not match on version %s, expected %s got %s", version, expected, got)
+		}
+	}
+}
+
+func TestItValidatesAgentModeVersionAndConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		version string
+		config  string
+		valid   bool
+	}{
+		{name: "supported", version: "v2.32.0", config: "scrape_configs: []\n", valid: true},
+		{name: "old version", version: "v2.31.1", config: "scrape_configs: []\n"},
+		{name: "not semantic version", version: "latest", config: "scrape_configs: []\n"},
+		{name: "rule files", version: "v2.35.0", config: "rule_files: [\"/etc/rules/*.yml\"]\n"},
+		{name: "alerting", version: "v2.35.0", config: "alerting:\n  alertmanagers: []\n"},
+	}
+
+	for _, tc := range cases {
+		c, err := promconfig.Parse(tc.config)
+		if err != nil {
+			t.Fatalf("unable to parse config, error %v", err)
+		}
+		pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: tc.version, Mode: v1alpha1.AgentMode}}
+		if err := validateMode(pm, c); (err == nil) != tc.valid {
+			t.Errorf("%s: unexpected validation result, error %v", tc.name, err)
+		}
+	}
+}
+
+func TestItSkipsModeValidationOnServerMode(t *testing.T) {
+	c, err := promconfig.Parse("rule_files: [\"/etc/rules/*.yml\"]\n")
+	if err != nil {
+		t.Fatalf("unable to parse config, error %v", err)
+	}
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1"}}
+	if err := validateMode(pm, c); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestItEnablesExpandExternalLabelsFeatureOnVersionRange(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"v2.26.0": false,
+		"v2.27.0": true,
+		"2.53.1":  true,
+		"v3.0.0":  false,
+	} {
+		pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: version}}
+		if got := contains(prometheusArgs(pm), "--enable-feature=expand-external-labels"); expected != got {
+			t.Errorf("expand external labels feature does not match on version %s, expected %t got %t", version, expected, got)
+		}
+	}
+}
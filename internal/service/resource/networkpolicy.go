@@ -0,0 +1,145 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/networking/v1"
+)
+
+const prometheusNetworkPolicyName = svc.MonitoringName + "-network-policy"
+const networkPolicyResourceName = "networkpolicies"
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+const operatorAppName = "prometheus-operator"
+
+type networkPolicy struct {
+	client    kubernetes.Interface
+	lister    listersV1.NetworkPolicyLister
+	namespace string
+	name      string
+}
+
+// NewNetworkPolicy instantiates prometheus network policy resource enforcer
+func NewNetworkPolicy(cl kubernetes.Interface, l listersV1.NetworkPolicyLister) svc.ResourceEnforcer {
+	return &networkPolicy{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusNetworkPolicyName,
+	}
+}
+
+// EnsureCreation checks network policy existence, if it's not found it will create it
+func (c *networkPolicy) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.NetworkPolicies(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get network policy %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion checks network policy existence, if it's it will delete it
+func (c *networkPolicy) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing network policy  %s", c.name)
+	err := c.client.NetworkingV1().NetworkPolicies(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to delete network policy, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated check if resource exists
+func (c *networkPolicy) IsCreated() (bool, error) {
+	_, err := c.lister.NetworkPolicies(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get network policy %w", err)
+	}
+
+	return true, nil
+}
+
+// IsRequired checks if PrometheusServer defines network policy
+func (c *networkPolicy) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.NetworkPolicy != nil
+}
+
+// Name returns resource enforcer target name
+func (c *networkPolicy) Name() string {
+	return networkPolicyResourceName
+}
+
+func (c *networkPolicy) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating network policy  %s", c.name)
+	port := intstr.FromInt(prometheusHttpPort)
+	protocol := corev1.ProtocolTCP
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": svc.MonitoringName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocol, Port: &port}},
+					From:  peers(obj.Spec.NetworkPolicy),
+				},
+			},
+		},
+	}
+
+	_, err := c.client.NetworkingV1().NetworkPolicies(c.namespace).Create(ctx, np, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create network policy, error %w", err)
+	}
+	return nil
+}
+
+// peers builds allowed sources, operator and Prometheus pods on monitoring namespace are always allowed
+func peers(spec *v1alpha1.NetworkPolicySpec) []networkingv1.NetworkPolicyPeer {
+	res := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": operatorAppName}}},
+		{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": svc.MonitoringName}}},
+	}
+	for _, ns := range spec.Namespaces {
+		res = append(res, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{namespaceNameLabel: ns},
+			},
+		})
+	}
+
+	if spec.NamespaceSelector != nil || spec.PodSelector != nil {
+		res = append(res, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: spec.NamespaceSelector,
+			PodSelector:       spec.PodSelector,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesNetworkPolicyOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Networking().V1().NetworkPolicies()
+
+	np := NewNetworkPolicy(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			NetworkPolicy: &v1alpha1.NetworkPolicySpec{
+				Namespaces:  []string{"grafana"},
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "thanos-query"}},
+			},
+		},
+	}
+	if err := np.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure network policy creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	v, ok := clActions[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	n, ok := v.GetObject().(*networkingv1.NetworkPolicy)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	rule := n.Spec.Ingress[0]
+	if expected, got := prometheusHttpPort, rule.Ports[0].Port.IntValue(); expected != got {
+		t.Errorf("port does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 4, len(rule.From); expected != got {
+		t.Fatalf("peers do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := operatorAppName, rule.From[0].PodSelector.MatchLabels["app"]; expected != got {
+		t.Errorf("operator peer does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := "grafana", rule.From[2].NamespaceSelector.MatchLabels[namespaceNameLabel]; expected != got {
+		t.Errorf("namespace does not match, expected %s got %s", expected, got)
+	}
+	if rule.From[3].PodSelector == nil {
+		t.Error("expected pod selector peer")
+	}
+}
+
+func TestItOnlyAllowsOperatorAndPrometheusPodsOnEmptyNetworkPolicy(t *testing.T) {
+	from := peers(&v1alpha1.NetworkPolicySpec{})
+	if expected, got := 2, len(from); expected != got {
+		t.Fatalf("peers do not match, expected %d got %d", expected, got)
+	}
+	for _, p := range from {
+		if p.PodSelector == nil || p.NamespaceSelector != nil || p.IPBlock != nil {
+			t.Errorf("expected monitoring namespace pod selector peer, got %v", p)
+		}
+	}
+}
+
+func TestItRemovesNetworkPolicyOnDeletionRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Networking().V1().NetworkPolicies()
+
+	np := NewNetworkPolicy(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := np.EnsureDeletion(ctx, &v1alpha1.PrometheusServer{}); err != nil {
+		t.Fatalf("unable to ensure network policy deletion, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	if expected, got := "delete", clActions[0].GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,181 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/apps/v1"
+)
+
+const nodeExporterName = "node-exporter"
+const nodeExporterResourceName = "node-exporter"
+const nodeExporterDefaultImage = "prom/node-exporter:v1.3.1"
+const nodeExporterPort = 9100
+
+type nodeExporter struct {
+	client    kubernetes.Interface
+	lister    listersV1.DaemonSetLister
+	namespace string
+	name      string
+}
+
+// NewNodeExporter instantiates node-exporter resource enforcer
+func NewNodeExporter(cl kubernetes.Interface, l listersV1.DaemonSetLister) svc.ResourceEnforcer {
+	return &nodeExporter{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      nodeExporterName,
+	}
+}
+
+// EnsureCreation checks node-exporter daemonset existence, if it's not found it will create it
+func (c *nodeExporter) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.DaemonSets(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get node-exporter daemonset %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion removes node-exporter daemonset and service
+func (c *nodeExporter) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing node-exporter %s", c.name)
+	err := c.client.AppsV1().DaemonSets(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete node-exporter daemonset, error %w", err)
+	}
+
+	err = c.client.CoreV1().Services(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete node-exporter service, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated checks node-exporter daemonset exists and its pods are ready
+func (c *nodeExporter) IsCreated() (bool, error) {
+	ds, err := c.lister.DaemonSets(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get node-exporter daemonset %w", err)
+	}
+
+	return ds.Status.NumberReady >= ds.Status.DesiredNumberScheduled, nil
+}
+
+// IsRequired checks if PrometheusServer components define node-exporter
+func (c *nodeExporter) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Components != nil && obj.Spec.Components.NodeExporter != nil
+}
+
+// Name returns resource enforcer target name
+func (c *nodeExporter) Name() string {
+	return nodeExporterResourceName
+}
+
+func (c *nodeExporter) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating node-exporter %s", c.name)
+	spec := obj.Spec.Components.NodeExporter
+	labels := map[string]string{"app": c.name}
+	nonRoot := true
+	user := int64(65534)
+	hostPathVolume := func(name, path string) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: path}}}
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					HostPID:     true,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: &nonRoot,
+						RunAsUser:    &user,
+					},
+					Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{
+						{
+							Name:  c.name,
+							Image: componentImage(spec, nodeExporterDefaultImage),
+							Args: []string{
+								"--path.procfs=/host/proc",
+								"--path.sysfs=/host/sys",
+								"--path.rootfs=/host/root",
+								fmt.Sprintf("--web.listen-address=:%d", nodeExporterPort),
+							},
+							Ports: []corev1.ContainerPort{
+								{Name: "metrics", ContainerPort: nodeExporterPort, HostPort: nodeExporterPort},
+							},
+							Resources: spec.Resources,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "proc", MountPath: "/host/proc", ReadOnly: true},
+								{Name: "sys", MountPath: "/host/sys", ReadOnly: true},
+								{Name: "root", MountPath: "/host/root", ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						hostPathVolume("proc", "/proc"),
+						hostPathVolume("sys", "/sys"),
+						hostPathVolume("root", "/"),
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := c.client.AppsV1().DaemonSets(c.namespace).Create(ctx, ds, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("unable to create node-exporter daemonset, error %w", err)
+	}
+
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
+			Ports:     []corev1.ServicePort{{Name: "metrics", Port: nodeExporterPort}},
+		},
+	}
+	_, err := c.client.CoreV1().Services(c.namespace).Create(ctx, s, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create node-exporter service, error %w", err)
+	}
+	return nil
+}
+
+// componentImage returns component image, default one is used when it's not overridden
+func componentImage(spec *v1alpha1.ComponentSpec, def string) string {
+	if spec.Image != "" {
+		return spec.Image
+	}
+	return def
+}
@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesNodeExporterDaemonSetAndServiceOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	ne := NewNodeExporter(clientSet, sif.Apps().V1().DaemonSets().Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Components: &v1alpha1.ComponentsSpec{NodeExporter: &v1alpha1.ComponentSpec{Image: "foo/node-exporter:v1"}},
+		},
+	}
+	if err := ne.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure node-exporter creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	ds, ok := clActions[0].(k8stest.CreateAction).GetObject().(*appsv1.DaemonSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	if expected, got := "foo/node-exporter:v1", ds.Spec.Template.Spec.Containers[0].Image; expected != got {
+		t.Errorf("image does not match, expected %s got %s", expected, got)
+	}
+	if !ds.Spec.Template.Spec.HostNetwork || !ds.Spec.Template.Spec.HostPID {
+		t.Error("expected host network and pid namespaces")
+	}
+
+	s, ok := clActions[1].(k8stest.CreateAction).GetObject().(*corev1.Service)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[1])
+	}
+	if expected, got := nodeExporterName, s.Name; expected != got {
+		t.Errorf("service name does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRequiresNodeExporterOnlyWhenDefined(t *testing.T) {
+	ne := NewNodeExporter(fake.NewSimpleClientset(), nil).(*nodeExporter)
+	if ne.IsRequired(&v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Components: &v1alpha1.ComponentsSpec{}}}) {
+		t.Error("node-exporter not expected as required")
+	}
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Components: &v1alpha1.ComponentsSpec{NodeExporter: &v1alpha1.ComponentSpec{}}}}
+	if !ne.IsRequired(pm) {
+		t.Error("node-exporter expected as required")
+	}
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promapi"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const podClientTimeout = time.Second * 10
+const webCAKey = "ca.crt"
+
+// PodClients builds Prometheus API clients to managed pods through their headless Service DNS names
+type PodClients struct {
+	secrets    listersV1.SecretLister
+	http       *http.Client
+	address    func(pod string) string
+	serverName func(pod string) string
+}
+
+// NewPodClients instantiates Prometheus pod API clients builder
+func NewPodClients(l listersV1.SecretLister) *PodClients {
+	return &PodClients{
+		secrets: l,
+		http:    &http.Client{Timeout: podClientTimeout},
+		address: func(pod string) string {
+			return fmt.Sprintf("%s:%d", podHost(pod), prometheusHttpPort)
+		},
+		serverName: podHost,
+	}
+}
+
+// client returns pod API client
+func (p *PodClients) client(obj *v1alpha1.PrometheusServer, pod string) (*promapi.Client, error) {
+	var username, password string
+	if webBasicAuth(obj) {
+		s, err := p.secrets.Secrets(service2.MonitoringNamespace).Get(prometheusWebCredentialsName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get web credentials secret, error %w", err)
+		}
+		username, password = WebUser, string(s.Data[webPasswordKey])
+	}
+
+	hc := p.http
+	if webTLS(obj) {
+		var err error
+		if hc, err = p.tlsClient(obj, pod); err != nil {
+			return nil, err
+		}
+	}
+
+	url := fmt.Sprintf("%s://%s%s", strings.ToLower(string(webScheme(obj))), p.address(pod), routePrefix(obj))
+	return promapi.NewClient(url, hc, username, password), nil
+}
+
+// tlsClient returns an http client trusting web TLS Secret CA and certificate
+func (p *PodClients) tlsClient(obj *v1alpha1.PrometheusServer, pod string) (*http.Client, error) {
+	name := obj.Spec.Web.TLSSecretRef.Name
+	s, err := p.secrets.Secrets(service2.MonitoringNamespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get web tls secret %s, error %w", name, err)
+	}
+
+	roots := x509.NewCertPool()
+	ca := roots.AppendCertsFromPEM(s.Data[webCAKey])
+	crt := roots.AppendCertsFromPEM(s.Data[corev1.TLSCertKey])
+	if !ca && !crt {
+		return nil, fmt.Errorf("web tls secret %s has no certificates", name)
+	}
+
+	return &http.Client{
+		Timeout: podClientTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:    roots,
+			ServerName: p.serverName(pod),
+			MinVersion: tls.VersionTLS12,
+		}},
+	}, nil
+}
+
+// selfScrapeAuthorization returns self scrape user basic Authorization header value
+func (p *PodClients) selfScrapeAuthorization() (string, error) {
+	s, err := p.secrets.Secrets(service2.MonitoringNamespace).Get(prometheusSelfScrapeName)
+	if err != nil {
+		return "", fmt.Errorf("unable to get self scrape secret, error %w", err)
+	}
+
+	credentials := fmt.Sprintf("%s:%s", selfScrapeUser, s.Data[webPasswordKey])
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+}
+
+// podHost returns pod DNS name through headless Service
+func podHost(pod string) string {
+	return fmt.Sprintf("%s.%s", pod, headlessServiceHost())
+}
+
+// healthy checks pod has any target up, pods without targets are healthy
+func (p *PodClients) healthy(ctx context.Context, obj *v1alpha1.PrometheusServer, pod string) (bool, error) {
+	cl, err := p.client(obj, pod)
+	if err != nil {
+		return false, err
+	}
+
+	targets, err := cl.Targets(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(targets) == 0 {
+		return true, nil
+	}
+
+	for _, t := range targets {
+		if t.Health == promapi.HealthUp {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// snapshot creates a pod TSDB snapshot, returning its name
+func (p *PodClients) snapshot(ctx context.Context, obj *v1alpha1.PrometheusServer, pod string) (string, error) {
+	cl, err := p.client(obj, pod)
+	if err != nil {
+		return "", err
+	}
+	return cl.Snapshot(ctx)
+}
+
+// reload requests pod config reload
+func (p *PodClients) reload(ctx context.Context, obj *v1alpha1.PrometheusServer, pod string) error {
+	cl, err := p.client(obj, pod)
+	if err != nil {
+		return err
+	}
+	return cl.Reload(ctx)
+}
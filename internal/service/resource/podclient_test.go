@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestItVerifiesPodCertificatesAgainstWebTLSSecret(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"activeTargets":[]}}`))
+	}))
+	defer srv.Close()
+
+	sif := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	secrets := sif.Core().V1().Secrets()
+	crt := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-tls", Namespace: service2.MonitoringNamespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: crt},
+	}
+	if err := secrets.Informer().GetIndexer().Add(s); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	pc := NewPodClients(secrets.Lister())
+	pc.address = func(pod string) string {
+		return strings.TrimPrefix(srv.URL, "https://")
+	}
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Web: &v1alpha1.WebSpec{TLSSecretRef: &corev1.LocalObjectReference{Name: "prometheus-tls"}},
+		},
+	}
+
+	// test server certificate is issued for example.com
+	pc.serverName = func(pod string) string { return "example.com" }
+	if _, err := pc.healthy(context.Background(), pm, "prometheus-server-0"); err != nil {
+		t.Fatalf("unexpected error checking pod health %v", err)
+	}
+
+	pc.serverName = podHost
+	if _, err := pc.healthy(context.Background(), pm, "prometheus-server-0"); err == nil {
+		t.Fatal("expected certificate verification error on pod name")
+	}
+}
@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/policy/v1"
+)
+
+const prometheusPodDisruptionBudgetName = svc.MonitoringName + "-pdb"
+const podDisruptionBudgetResourceName = "poddisruptionbudgets"
+
+type podDisruptionBudget struct {
+	client    kubernetes.Interface
+	lister    listersV1.PodDisruptionBudgetLister
+	namespace string
+	name      string
+}
+
+// NewPodDisruptionBudget instantiates prometheus pod disruption budget resource enforcer, shared by both colors
+func NewPodDisruptionBudget(cl kubernetes.Interface, l listersV1.PodDisruptionBudgetLister) svc.ResourceEnforcer {
+	return &podDisruptionBudget{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusPodDisruptionBudgetName,
+	}
+}
+
+// EnsureCreation checks pod disruption budget existence, if it's not found it will create it
+func (c *podDisruptionBudget) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.PodDisruptionBudgets(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get pod disruption budget %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion checks pod disruption budget existence, if it's it will delete it
+func (c *podDisruptionBudget) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("removing pod disruption budget  %s", c.name)
+	err := c.client.PolicyV1().PodDisruptionBudgets(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to delete pod disruption budget, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated check if resource exists
+func (c *podDisruptionBudget) IsCreated() (bool, error) {
+	_, err := c.lister.PodDisruptionBudgets(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to get pod disruption budget %w", err)
+	}
+
+	return true, nil
+}
+
+// IsRequired checks if PrometheusServer defines pod disruption budget
+func (c *podDisruptionBudget) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.PodDisruptionBudget != nil
+}
+
+// Name returns resource enforcer target name
+func (c *podDisruptionBudget) Name() string {
+	return podDisruptionBudgetResourceName
+}
+
+func (c *podDisruptionBudget) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating pod disruption budget  %s", c.name)
+	minAvailable := minAvailable(obj)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": svc.MonitoringName},
+			},
+		},
+	}
+
+	_, err := c.client.PolicyV1().PodDisruptionBudgets(c.namespace).Create(ctx, pdb, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create pod disruption budget, error %w", err)
+	}
+	return nil
+}
+
+// minAvailable defaults to one less than replicas on all shards
+func minAvailable(obj *v1alpha1.PrometheusServer) intstr.IntOrString {
+	if m := obj.Spec.PodDisruptionBudget.MinAvailable; m != nil {
+		return *m
+	}
+	return intstr.FromInt(int(replicas(obj)*shards(obj) - 1))
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesPodDisruptionBudgetSizedFromReplicasOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Policy().V1().PodDisruptionBudgets()
+
+	pdb := NewPodDisruptionBudget(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	replicas := int32(3)
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Replicas:            &replicas,
+			PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetSpec{},
+		},
+	}
+	if err := pdb.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure pod disruption budget creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	v, ok := clActions[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	p, ok := v.GetObject().(*policyv1.PodDisruptionBudget)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	if expected, got := 2, p.Spec.MinAvailable.IntValue(); expected != got {
+		t.Errorf("min available does not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItRequiresPodDisruptionBudgetOnlyWhenDefined(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Policy().V1().PodDisruptionBudgets()
+
+	pdb := NewPodDisruptionBudget(clientSet, i.Lister()).(*podDisruptionBudget)
+	if pdb.IsRequired(&v1alpha1.PrometheusServer{}) {
+		t.Error("pod disruption budget not expected as required")
+	}
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetSpec{}}}
+	if !pdb.IsRequired(pm) {
+		t.Error("pod disruption budget expected as required")
+	}
+}
@@ -0,0 +1,227 @@
+package resource
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const remoteWriteKey = "remote_write"
+const remoteReadKey = "remote_read"
+const prometheusSecretsPath = "/etc/prometheus-secrets/"
+const secretVolumePrefix = "secret-"
+const maxVolumeNameLength = 63
+
+// appendRemoteEndpoints renders PrometheusServer remote write and read entries, secrets are referenced as mounted files
+func appendRemoteEndpoints(c *promconfig.Config, obj *v1alpha1.PrometheusServer) {
+	for _, rw := range obj.Spec.RemoteWrite {
+		c.Append(remoteWriteKey, remoteWrite(rw))
+	}
+	for _, rr := range obj.Spec.RemoteRead {
+		c.Append(remoteReadKey, remoteRead(rr))
+	}
+}
+
+func remoteWrite(rw v1alpha1.RemoteWriteSpec) yaml.MapSlice {
+	res := yaml.MapSlice{{Key: "url", Value: rw.URL}}
+	res = appendIfSet(res, "name", rw.Name)
+	res = appendIfSet(res, "remote_timeout", rw.RemoteTimeout)
+	res = appendAuth(res, rw.BasicAuth, rw.BearerToken, rw.TLSConfig)
+
+	if q := rw.QueueConfig; q != nil {
+		qc := yaml.MapSlice{}
+		qc = appendIfSet(qc, "capacity", q.Capacity)
+		qc = appendIfSet(qc, "min_shards", q.MinShards)
+		qc = appendIfSet(qc, "max_shards", q.MaxShards)
+		qc = appendIfSet(qc, "max_samples_per_send", q.MaxSamplesPerSend)
+		qc = appendIfSet(qc, "batch_send_deadline", q.BatchSendDeadline)
+		qc = appendIfSet(qc, "min_backoff", q.MinBackoff)
+		qc = appendIfSet(qc, "max_backoff", q.MaxBackoff)
+		res = append(res, yaml.MapItem{Key: "queue_config", Value: qc})
+	}
+
+	if len(rw.WriteRelabelConfigs) > 0 {
+		var relabels []interface{}
+		for _, r := range rw.WriteRelabelConfigs {
+			relabels = append(relabels, relabelConfig(r))
+		}
+		res = append(res, yaml.MapItem{Key: "write_relabel_configs", Value: relabels})
+	}
+
+	return res
+}
+
+func remoteRead(rr v1alpha1.RemoteReadSpec) yaml.MapSlice {
+	res := yaml.MapSlice{{Key: "url", Value: rr.URL}}
+	res = appendIfSet(res, "name", rr.Name)
+	res = appendIfSet(res, "remote_timeout", rr.RemoteTimeout)
+	res = appendIfSet(res, "read_recent", rr.ReadRecent)
+	if len(rr.RequiredMatchers) > 0 {
+		res = append(res, yaml.MapItem{Key: "required_matchers", Value: rr.RequiredMatchers})
+	}
+
+	return appendAuth(res, rr.BasicAuth, rr.BearerToken, rr.TLSConfig)
+}
+
+func relabelConfig(r v1alpha1.RelabelConfig) yaml.MapSlice {
+	res := yaml.MapSlice{}
+	if len(r.SourceLabels) > 0 {
+		res = append(res, yaml.MapItem{Key: "source_labels", Value: r.SourceLabels})
+	}
+	res = appendIfSet(res, "separator", r.Separator)
+	res = appendIfSet(res, "target_label", r.TargetLabel)
+	res = appendIfSet(res, "regex", r.Regex)
+	res = appendIfSet(res, "modulus", r.Modulus)
+	res = appendIfSet(res, "replacement", r.Replacement)
+	return appendIfSet(res, "action", r.Action)
+}
+
+func appendAuth(res yaml.MapSlice, basic *v1alpha1.BasicAuth, bearer *v1alpha1.SecretKeyRef, tls *v1alpha1.TLSConfig) yaml.MapSlice {
+	if basic != nil {
+		res = append(res, yaml.MapItem{Key: "basic_auth", Value: yaml.MapSlice{
+			{Key: "username", Value: basic.Username},
+			{Key: "password_file", Value: secretFile(basic.Password)},
+		}})
+	}
+
+	if bearer != nil {
+		res = append(res, yaml.MapItem{Key: "authorization", Value: yaml.MapSlice{
+			{Key: "type", Value: "Bearer"},
+			{Key: "credentials_file", Value: secretFile(*bearer)},
+		}})
+	}
+
+	if tls != nil {
+		tc := yaml.MapSlice{}
+		if tls.CA != nil {
+			tc = append(tc, yaml.MapItem{Key: "ca_file", Value: secretFile(*tls.CA)})
+		}
+		if tls.Cert != nil {
+			tc = append(tc, yaml.MapItem{Key: "cert_file", Value: secretFile(*tls.Cert)})
+		}
+		if tls.Key != nil {
+			tc = append(tc, yaml.MapItem{Key: "key_file", Value: secretFile(*tls.Key)})
+		}
+		tc = appendIfSet(tc, "server_name", tls.ServerName)
+		tc = appendIfSet(tc, "insecure_skip_verify", tls.InsecureSkipVerify)
+		res = append(res, yaml.MapItem{Key: "tls_config", Value: tc})
+	}
+
+	return res
+}
+
+// appendIfSet adds key when value is not its zero value
+func appendIfSet(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return m
+		}
+	case int:
+		if v == 0 {
+			return m
+		}
+	case uint64:
+		if v == 0 {
+			return m
+		}
+	case bool:
+		if !v {
+			return m
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
+
+// secretFile returns the path where secret key is mounted on Prometheus container
+func secretFile(ref v1alpha1.SecretKeyRef) string {
+	return path.Join(prometheusSecretsPath, ref.Name, ref.Key)
+}
+
+// remoteSecrets returns Secret names referenced by remote endpoints and federation, in order of appearance
+func remoteSecrets(obj *v1alpha1.PrometheusServer) []string {
+	var refs []*v1alpha1.SecretKeyRef
+	collect := func(basic *v1alpha1.BasicAuth, bearer *v1alpha1.SecretKeyRef, tls *v1alpha1.TLSConfig) {
+		if basic != nil {
+			refs = append(refs, &basic.Password)
+		}
+		refs = append(refs, bearer)
+		if tls != nil {
+			refs = append(refs, tls.CA, tls.Cert, tls.Key)
+		}
+	}
+	for _, rw := range obj.Spec.RemoteWrite {
+		collect(rw.BasicAuth, rw.BearerToken, rw.TLSConfig)
+	}
+	for _, rr := range obj.Spec.RemoteRead {
+		collect(rr.BasicAuth, rr.BearerToken, rr.TLSConfig)
+	}
+	if f := obj.Spec.Federation; f != nil {
+		collect(f.BasicAuth, nil, f.TLSConfig)
+	}
+
+	var res []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if ref == nil || seen[ref.Name] {
+			continue
+		}
+		seen[ref.Name] = true
+		res = append(res, ref.Name)
+	}
+	return res
+}
+
+// configSecrets returns Secret names referenced by remote endpoints and config template placeholders
+func configSecrets(obj *v1alpha1.PrometheusServer) []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, name := range append(remoteSecrets(obj), templateSecrets(obj)...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	return res
+}
+
+// secretVolumes mounts each referenced Secret as a volume
+func secretVolumes(obj *v1alpha1.PrometheusServer) []corev1.Volume {
+	var res []corev1.Volume
+	for _, name := range configSecrets(obj) {
+		res = append(res, corev1.Volume{
+			Name: secretVolumeName(name),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: name},
+			},
+		})
+	}
+	return res
+}
+
+func secretVolumeMounts(obj *v1alpha1.PrometheusServer) []corev1.VolumeMount {
+	var res []corev1.VolumeMount
+	for _, name := range configSecrets(obj) {
+		res = append(res, corev1.VolumeMount{
+			Name:      secretVolumeName(name),
+			MountPath: path.Join(prometheusSecretsPath, name),
+			ReadOnly:  true,
+		})
+	}
+	return res
+}
+
+// secretVolumeName builds a valid volume name from Secret name, dots are not allowed on volume names
+func secretVolumeName(secret string) string {
+	name := fmt.Sprintf("%s%s", secretVolumePrefix, strings.ReplaceAll(secret, ".", "-"))
+	if len(name) > maxVolumeNameLength {
+		name = strings.TrimRight(name[:maxVolumeNameLength], "-")
+	}
+	return name
+}
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestItRendersRemoteEndpointsWithSecretFiles(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version: "v2.35.0",
+			Config:  "scrape_configs: []\n",
+			RemoteWrite: []v1alpha1.RemoteWriteSpec{
+				{
+					URL:         "https://cortex.example.com/api/v1/push",
+					QueueConfig: &v1alpha1.QueueConfig{MaxShards: 10},
+					WriteRelabelConfigs: []v1alpha1.RelabelConfig{
+						{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+					},
+					BasicAuth: &v1alpha1.BasicAuth{Username: "tenant", Password: v1alpha1.SecretKeyRef{Name: "cortex", Key: "password"}},
+					TLSConfig: &v1alpha1.TLSConfig{CA: &v1alpha1.SecretKeyRef{Name: "cortex-tls", Key: "ca.crt"}},
+				},
+			},
+			RemoteRead: []v1alpha1.RemoteReadSpec{
+				{
+					URL:         "https://cortex.example.com/api/v1/read",
+					BearerToken: &v1alpha1.SecretKeyRef{Name: "cortex", Key: "token"},
+				},
+			},
+		},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building config, error %v", err)
+	}
+
+	expected := `scrape_configs: []
+global:
+  external_labels:
+    prometheus_replica: ${POD_NAME}
+    prometheus: default/prometheus
+remote_write:
+- url: https://cortex.example.com/api/v1/push
+  basic_auth:
+    username: tenant
+    password_file: /etc/prometheus-secrets/cortex/password
+  tls_config:
+    ca_file: /etc/prometheus-secrets/cortex-tls/ca.crt
+  queue_config:
+    max_shards: 10
+  write_relabel_configs:
+  - source_labels:
+    - __name__
+    regex: go_.*
+    action: drop
+remote_read:
+- url: https://cortex.example.com/api/v1/read
+  authorization:
+    type: Bearer
+    credentials_file: /etc/prometheus-secrets/cortex/token
+`
+	if expected != cf {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+
+	volumes := secretVolumes(pm)
+	if expected, got := 2, len(volumes); expected != got {
+		t.Fatalf("secret volumes do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "cortex", volumes[0].Secret.SecretName; expected != got {
+		t.Errorf("secret name does not match, expected %s got %s", expected, got)
+	}
+	mounts := secretVolumeMounts(pm)
+	if expected, got := "/etc/prometheus-secrets/cortex-tls", mounts[1].MountPath; expected != got {
+		t.Errorf("mount path does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItBuildsValidSecretVolumeNames(t *testing.T) {
+	if expected, got := "secret-cortex-example-com", secretVolumeName("cortex.example.com"); expected != got {
+		t.Errorf("volume name does not match, expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,241 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const configRevisionResourceName = "config-revisions"
+const configRevisionLabel = "k8slab.info/config-revision"
+const configRevisionTemplateKey = "template"
+const configRevisionHashLength = 10
+const defaultRevisionHistoryLimit = 10
+
+// ConfigRevisions keeps applied PrometheusServer configs on immutable ConfigMaps named after config hash
+type ConfigRevisions struct {
+	client    kubernetes.Interface
+	lister    listersV1.ConfigMapLister
+	namespace string
+	name      string
+}
+
+// NewConfigRevisions instantiates config revisions resource enforcer and spec resolver
+func NewConfigRevisions(cl kubernetes.Interface, l listersV1.ConfigMapLister) *ConfigRevisions {
+	return &ConfigRevisions{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusConfigMapName + "-rev",
+	}
+}
+
+// EnsureCreation records applied config as the last revision, revisions over history limit are removed
+func (c *ConfigRevisions) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	hash := configHash(obj)
+	name := c.name + "-" + hash
+	if _, err := c.lister.ConfigMaps(c.namespace).Get(name); apierrors.IsNotFound(err) {
+		if err := c.create(ctx, obj, name, hash); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("unable to get config revision %s %w", name, err)
+	}
+
+	revisions := obj.Status.ConfigRevisions
+	if len(revisions) > 0 && revisions[len(revisions)-1].Hash == hash {
+		return nil
+	}
+
+	var revision, last int64
+	var res []v1alpha1.ConfigRevision
+	for _, r := range revisions {
+		if r.Revision > last {
+			last = r.Revision
+		}
+		if r.Hash == hash {
+			revision = r.Revision
+			continue
+		}
+		res = append(res, r)
+	}
+	if revision == 0 {
+		revision = last + 1
+	}
+	res = append(res, v1alpha1.ConfigRevision{Revision: revision, Hash: hash, Name: name, Timestamp: metav1.Now()})
+
+	for int32(len(res)) > revisionHistoryLimit(obj) {
+		log.Debugf("removing config revision %s", res[0].Name)
+		err := c.client.CoreV1().ConfigMaps(c.namespace).Delete(ctx, res[0].Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete config revision %s, error %w", res[0].Name, err)
+		}
+		res = res[1:]
+	}
+	obj.Status.ConfigRevisions = res
+
+	return nil
+}
+
+// EnsureDeletion removes config revisions on PrometheusServer termination, revisions are kept on reloads
+func (c *ConfigRevisions) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	cms, err := c.list()
+	if err != nil {
+		return err
+	}
+
+	for _, cm := range cms {
+		log.Debugf("removing config revision %s", cm.Name)
+		err := c.client.CoreV1().ConfigMaps(c.namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete config revision %s, error %w", cm.Name, err)
+		}
+	}
+	return nil
+}
+
+// IsCreated check if any config revision exists
+func (c *ConfigRevisions) IsCreated() (bool, error) {
+	cms, err := c.list()
+	if err != nil {
+		return false, err
+	}
+
+	return len(cms) > 0, nil
+}
+
+// UpdatesInPlace config revisions are kept on reloads
+func (c *ConfigRevisions) UpdatesInPlace() bool {
+	return true
+}
+
+// EnsureColorCreation does nothing, candidate config is recorded as a revision once it's switched as active color
+func (c *ConfigRevisions) EnsureColorCreation(_ context.Context, _ *v1alpha1.PrometheusServer, _ string) error {
+	return nil
+}
+
+// IsColorReady returns true, config revisions do not gate candidate readiness
+func (c *ConfigRevisions) IsColorReady(_ context.Context, _ *v1alpha1.PrometheusServer, _ string) (bool, error) {
+	return true, nil
+}
+
+// EnsureColorDeletion does nothing, revisions are kept when a color is removed
+func (c *ConfigRevisions) EnsureColorDeletion(_ context.Context, _ *v1alpha1.PrometheusServer, _ string) error {
+	return nil
+}
+
+// Name returns resource enforcer target name
+func (c *ConfigRevisions) Name() string {
+	return configRevisionResourceName
+}
+
+// Resolve replaces spec by rollbackTo revision config, or by last known good spec on rolled back generations
+func (c *ConfigRevisions) Resolve(obj *v1alpha1.PrometheusServer) error {
+	if kg := obj.Status.LastKnownGood; kg != nil && svc.IsRolledBack(obj) {
+		obj.Spec.Version = kg.Version
+		restoreImage(obj, kg)
+		if kg.ConfigRevision == 0 {
+			return nil
+		}
+		return c.restore(obj, kg.ConfigRevision)
+	}
+
+	if obj.Spec.RollbackTo == nil {
+		return nil
+	}
+	return c.restore(obj, *obj.Spec.RollbackTo)
+}
+
+// restoreImage replaces spec image repository and digest by known good ones, pull settings are kept
+func restoreImage(obj *v1alpha1.PrometheusServer, kg *v1alpha1.KnownGoodSpec) {
+	if obj.Spec.Image == nil {
+		if kg.Repository == "" && kg.Digest == "" {
+			return
+		}
+		obj.Spec.Image = &v1alpha1.ImageSpec{}
+	}
+	obj.Spec.Image.Repository = kg.Repository
+	obj.Spec.Image.Digest = kg.Digest
+}
+
+// restore replaces spec config by the one from revision
+func (c *ConfigRevisions) restore(obj *v1alpha1.PrometheusServer, revision int64) error {
+	for _, r := range obj.Status.ConfigRevisions {
+		if r.Revision != revision {
+			continue
+		}
+
+		cm, err := c.lister.ConfigMaps(c.namespace).Get(r.Name)
+		if err != nil {
+			return fmt.Errorf("unable to get config revision %d, error %w", r.Revision, err)
+		}
+		obj.Spec.Config = cm.Data[prometheusConfigMapKey]
+		obj.Spec.TemplateConfig = cm.Data[configRevisionTemplateKey] == strconv.FormatBool(true)
+		return nil
+	}
+
+	return fmt.Errorf("config revision %d not found", revision)
+}
+
+func (c *ConfigRevisions) create(ctx context.Context, obj *v1alpha1.PrometheusServer, name, hash string) error {
+	log.Debugf("creating config revision %s", name)
+	immutable := true
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+			Labels:    map[string]string{"app": svc.MonitoringName, configRevisionLabel: hash},
+		},
+		Immutable: &immutable,
+		Data: map[string]string{
+			prometheusConfigMapKey:    obj.Spec.Config,
+			configRevisionTemplateKey: strconv.FormatBool(obj.Spec.TemplateConfig),
+		},
+	}
+	_, err := c.client.CoreV1().ConfigMaps(c.namespace).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create config revision, error %w", err)
+	}
+	return nil
+}
+
+func (c *ConfigRevisions) list() ([]*corev1.ConfigMap, error) {
+	// config revision label is a valid constant key, requirement never fails
+	r, _ := labels.NewRequirement(configRevisionLabel, selection.Exists, nil)
+	cms, err := c.lister.ConfigMaps(c.namespace).List(labels.NewSelector().Add(*r))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list config revisions %w", err)
+	}
+	return cms, nil
+}
+
+// configHash identifies PrometheusServer config, template flag included
+func configHash(obj *v1alpha1.PrometheusServer) string {
+	h := sha256.Sum256([]byte(strconv.FormatBool(obj.Spec.TemplateConfig) + "\n" + obj.Spec.Config))
+	return hex.EncodeToString(h[:])[:configRevisionHashLength]
+}
+
+// revisionHistoryLimit returns config revisions kept, defaults to 10
+func revisionHistoryLimit(obj *v1alpha1.PrometheusServer) int32 {
+	if obj.Spec.RevisionHistoryLimit == nil {
+		return defaultRevisionHistoryLimit
+	}
+	return *obj.Spec.RevisionHistoryLimit
+}
@@ -0,0 +1,157 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItRecordsAppliedConfigAsImmutableRevision(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	rev := NewConfigRevisions(clientSet, listersV1.NewConfigMapLister(newIndexer(t)))
+	pm := getFakePrometheusServer("default", "prometheus")
+
+	if err := rev.EnsureCreation(context.Background(), pm); err != nil {
+		t.Fatalf("unable to ensure config revision creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	cm, ok := clActions[0].(k8stest.CreateAction).GetObject().(*corev1.ConfigMap)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	if cm.Immutable == nil || !*cm.Immutable {
+		t.Error("expected immutable config revision")
+	}
+	if expected, got := "prometheus-server-config-rev-"+configHash(pm), cm.Name; expected != got {
+		t.Errorf("name does not match, expected %s got %s", expected, got)
+	}
+
+	if expected, got := 1, len(pm.Status.ConfigRevisions); expected != got {
+		t.Fatalf("revisions do not match, expected %d got %d", expected, got)
+	}
+	if r := pm.Status.ConfigRevisions[0]; r.Revision != 1 || r.Hash != configHash(pm) || r.Name != cm.Name {
+		t.Errorf("unexpected revision %v", r)
+	}
+}
+
+func TestItKeepsRevisionNumberOnReappliedConfigAndPrunesHistory(t *testing.T) {
+	limit := int32(2)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Config = "bar"
+	bar := configHash(pm)
+	pm.Spec.Config = "foo"
+	pm.Spec.RevisionHistoryLimit = &limit
+	pm.Status.ConfigRevisions = []v1alpha1.ConfigRevision{
+		{Revision: 1, Hash: configHash(pm), Name: "rev-foo"},
+		{Revision: 2, Hash: bar, Name: "rev-bar"},
+	}
+
+	clientSet := fake.NewSimpleClientset()
+	rev := NewConfigRevisions(clientSet, listersV1.NewConfigMapLister(newIndexer(t)))
+	if err := rev.EnsureCreation(context.Background(), pm); err != nil {
+		t.Fatalf("unable to ensure config revision creation, error %v", err)
+	}
+
+	revisions := pm.Status.ConfigRevisions
+	if expected, got := 2, len(revisions); expected != got {
+		t.Fatalf("revisions do not match, expected %d got %d", expected, got)
+	}
+	if revisions[0].Revision != 2 || revisions[1].Revision != 1 {
+		t.Errorf("unexpected revisions order %v", revisions)
+	}
+
+	pm.Spec.Config = "baz"
+	if err := rev.EnsureCreation(context.Background(), pm); err != nil {
+		t.Fatalf("unable to ensure config revision creation, error %v", err)
+	}
+	revisions = pm.Status.ConfigRevisions
+	if expected, got := 2, len(revisions); expected != got {
+		t.Fatalf("revisions do not match, expected %d got %d", expected, got)
+	}
+	if revisions[0].Revision != 1 || revisions[1].Revision != 3 {
+		t.Errorf("unexpected revisions %v", revisions)
+	}
+
+	var deleted []string
+	for _, a := range clientSet.Actions() {
+		if d, ok := a.(k8stest.DeleteAction); ok {
+			deleted = append(deleted, d.GetName())
+		}
+	}
+	if len(deleted) != 1 || deleted[0] != "rev-bar" {
+		t.Errorf("expected oldest revision removal, got %v", deleted)
+	}
+}
+
+func TestItResolvesSpecConfigFromRollbackRevision(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rev-foo", Namespace: service2.MonitoringNamespace},
+		Data:       map[string]string{prometheusConfigMapKey: "foo", configRevisionTemplateKey: "true"},
+	}
+	rev := NewConfigRevisions(clientSet, listersV1.NewConfigMapLister(newIndexer(t, cm)))
+
+	rollbackTo := int64(1)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Config = "bar"
+	pm.Spec.RollbackTo = &rollbackTo
+	pm.Status.ConfigRevisions = []v1alpha1.ConfigRevision{{Revision: 1, Hash: "foo", Name: "rev-foo"}}
+	if err := rev.Resolve(pm); err != nil {
+		t.Fatalf("unexpected error resolving spec %v", err)
+	}
+	if pm.Spec.Config != "foo" || !pm.Spec.TemplateConfig {
+		t.Errorf("unexpected resolved spec %v", pm.Spec)
+	}
+
+	rollbackTo = 2
+	if err := rev.Resolve(pm); err == nil {
+		t.Fatal("expected revision not found error")
+	}
+}
+
+func TestItResolvesLastKnownGoodSpecOnRolledBackGeneration(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rev-foo", Namespace: service2.MonitoringNamespace},
+		Data:       map[string]string{prometheusConfigMapKey: "foo", configRevisionTemplateKey: "false"},
+	}
+	rev := NewConfigRevisions(clientSet, listersV1.NewConfigMapLister(newIndexer(t, cm)))
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Config = "bar"
+	pm.Generation = 2
+	pm.Status.ConfigRevisions = []v1alpha1.ConfigRevision{{Revision: 1, Hash: "foo", Name: "rev-foo"}}
+	pm.Status.LastKnownGood = &v1alpha1.KnownGoodSpec{Version: "v2.34.0", Repository: "prom/prometheus", ConfigRevision: 1}
+	pm.Spec.Image = &v1alpha1.ImageSpec{Repository: "registry.internal/prom/prometheus", Digest: "sha256:bad", PullPolicy: corev1.PullAlways}
+	pm.Status.Conditions = []metav1.Condition{{Type: v1alpha1.RolledBack, Status: metav1.ConditionTrue, ObservedGeneration: 1}}
+
+	cp := pm.DeepCopy()
+	if err := rev.Resolve(cp); err != nil {
+		t.Fatalf("unexpected error resolving spec %v", err)
+	}
+	if cp.Spec.Config != "bar" || cp.Spec.Version != "v2.35.0" {
+		t.Errorf("previous generation rollback applied, got %v", cp.Spec)
+	}
+
+	pm.Status.Conditions[0].ObservedGeneration = 2
+	if err := rev.Resolve(pm); err != nil {
+		t.Fatalf("unexpected error resolving spec %v", err)
+	}
+	if pm.Spec.Config != "foo" || pm.Spec.Version != "v2.34.0" {
+		t.Errorf("unexpected resolved spec %v", pm.Spec)
+	}
+	if img := pm.Spec.Image; img.Repository != "prom/prometheus" || img.Digest != "" || img.PullPolicy != corev1.PullAlways {
+		t.Errorf("unexpected resolved image %v", img)
+	}
+}
@@ -0,0 +1,174 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	rbac "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/rbac/v1"
+)
+
+const roleName = service2.MonitoringName + "-role"
+const roleResourceName = "roles"
+const rbacLabel = "k8slab.info/rbac"
+
+// role grants Prometheus namespaced read access on each namespace listed on PrometheusServer rbac scope
+type role struct {
+	client   kubernetes.Interface
+	lister   listersV1.RoleLister
+	name     string
+	selector labels.Set
+}
+
+// NewRole instantiates role resource enforcer, roles are only created on namespaces rbac scope
+func NewRole(cl kubernetes.Interface, l listersV1.RoleLister) service2.ResourceEnforcer {
+	return &role{
+		client:   cl,
+		lister:   l,
+		name:     roleName,
+		selector: labels.Set{"app": service2.MonitoringName, rbacLabel: roleName},
+	}
+}
+
+// EnsureCreation checks role existence on each scope namespace, if it's not found it will create it
+func (c *role) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if len(rbacNamespaces(obj)) == 0 {
+		return fmt.Errorf("namespaces rbac scope requires at least one namespace")
+	}
+
+	for _, ns := range rbacNamespaces(obj) {
+		_, err := c.lister.Roles(ns).Get(c.name)
+		if err == nil {
+			continue
+		}
+
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get role on namespace %s %w", ns, err)
+		}
+
+		if err := c.create(ctx, ns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureDeletion removes all operator roles
+func (c *role) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	roles, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list roles, error %w", err)
+	}
+
+	for _, r := range roles {
+		log.Debugf("removing role %s on namespace %s", r.Name, r.Namespace)
+		err := c.client.RbacV1().Roles(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete role on namespace %s, error %w", r.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// IsCreated check if any operator role exists, roles are created all together on creation
+func (c *role) IsCreated() (bool, error) {
+	roles, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return false, fmt.Errorf("unable to list roles %w", err)
+	}
+
+	return len(roles) > 0, nil
+}
+
+// IsRequired checks if PrometheusServer is namespaces scoped
+func (c *role) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return rbacScope(obj) == v1alpha1.NamespacesScope
+}
+
+// Name returns resource enforcer target name
+func (c *role) Name() string {
+	return roleResourceName
+}
+
+// IsDrifted checks if operator roles are found on other namespaces than the ones on PrometheusServer rbac scope
+func (c *role) IsDrifted(obj *v1alpha1.PrometheusServer) (bool, error) {
+	roles, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return false, fmt.Errorf("unable to list roles %w", err)
+	}
+
+	var namespaces []string
+	for _, r := range roles {
+		namespaces = append(namespaces, r.Namespace)
+	}
+	return namespacesDrifted(rbacNamespaces(obj), namespaces), nil
+}
+
+func (c *role) create(ctx context.Context, namespace string) error {
+	log.Debugf("creating role %s on namespace %s", c.name, namespace)
+	r := &rbac.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: namespace,
+			Labels:    c.selector,
+		},
+		Rules: namespacedRules(),
+	}
+
+	_, err := c.client.RbacV1().Roles(namespace).Create(ctx, r, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create role on namespace %s, error %w", namespace, err)
+	}
+	return nil
+}
+
+// namespacedRules grants read access on namespaced resources discovered by Prometheus
+func namespacedRules() []rbac.PolicyRule {
+	return []rbac.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{
+				"services",
+				"endpoints",
+				"pods",
+			},
+			Verbs: []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{
+				"ingresses",
+			},
+			Verbs: []string{"get", "list", "watch"},
+		},
+	}
+}
+
+func rbacScope(obj *v1alpha1.PrometheusServer) string {
+	if obj.Spec.RBAC == nil || obj.Spec.RBAC.Scope == "" {
+		return v1alpha1.ClusterScope
+	}
+	return obj.Spec.RBAC.Scope
+}
+
+// namespacesDrifted checks if current namespaces differ from desired ones, order does not matter
+func namespacesDrifted(desired, current []string) bool {
+	return !sets.NewString(desired...).Equal(sets.NewString(current...))
+}
+
+// rbacNamespaces returns namespaces Prometheus is allowed to access, empty on cluster scope
+func rbacNamespaces(obj *v1alpha1.PrometheusServer) []string {
+	if rbacScope(obj) != v1alpha1.NamespacesScope {
+		return nil
+	}
+	return obj.Spec.RBAC.Namespaces
+}
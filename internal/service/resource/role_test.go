@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestItCreatesRolesOnEachScopeNamespaceOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().Roles()
+
+	r := NewRole(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a", "team-b"}},
+		},
+	}
+	if err := r.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure role creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	for idx, ns := range []string{"team-a", "team-b"} {
+		action := clActions[idx]
+		if expected, got := "create", action.GetVerb(); expected != got {
+			t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+		}
+		if expected, got := ns, action.GetNamespace(); expected != got {
+			t.Errorf("unexpected namespace, expected %s got %s", expected, got)
+		}
+	}
+}
+
+func TestItRemovesRolesFromAllNamespacesOnDeletionRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().Roles()
+
+	r := NewRole(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	for _, ns := range []string{"team-a", "removed-from-scope"} {
+		ro := &rbac.Role{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: ns, Labels: r.(*role).selector}}
+		if err := i.Informer().GetIndexer().Add(ro); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a"}},
+		},
+	}
+	if err := r.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure role deletion, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	for _, action := range clActions {
+		if expected, got := "delete", action.GetVerb(); expected != got {
+			t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+		}
+	}
+}
+
+func TestItRequiresRolesOnlyOnNamespacesScope(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+
+	r := NewRole(clientSet, sif.Rbac().V1().Roles().Lister()).(*role)
+	cr := NewClusterRole(clientSet, sif.Rbac().V1().ClusterRoles().Lister()).(*clusterRole)
+
+	pm := &v1alpha1.PrometheusServer{}
+	if r.IsRequired(pm) {
+		t.Error("role not expected as required on cluster scope")
+	}
+	if !cr.IsRequired(pm) {
+		t.Error("cluster role expected as required on cluster scope")
+	}
+
+	pm.Spec.RBAC = &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a"}}
+	if !r.IsRequired(pm) {
+		t.Error("role expected as required on namespaces scope")
+	}
+	if cr.IsRequired(pm) {
+		t.Error("cluster role not expected as required on namespaces scope")
+	}
+}
+
+func TestItDetectsRoleDriftFromScopeNamespaces(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().Roles()
+
+	r := NewRole(clientSet, i.Lister()).(*role)
+	ro := &rbac.Role{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: "team-a", Labels: r.selector}}
+	if err := i.Informer().GetIndexer().Add(ro); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	for namespaces, expected := range map[string]bool{"team-a": false, "team-a,team-b": true, "team-b": true} {
+		pm := &v1alpha1.PrometheusServer{
+			Spec: v1alpha1.PrometheusServerSpec{
+				RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: strings.Split(namespaces, ",")},
+			},
+		}
+		drifted, err := r.IsDrifted(pm)
+		if err != nil {
+			t.Fatalf("unexpected error checking drift, error %v", err)
+		}
+		if expected != drifted {
+			t.Errorf("drift on namespaces %s does not match, expected %t got %t", namespaces, expected, drifted)
+		}
+	}
+
+	drifted, err := r.IsDrifted(&v1alpha1.PrometheusServer{})
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if !drifted {
+		t.Error("roles left on cluster scope expected as drifted")
+	}
+}
@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	rbac "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/rbac/v1"
+)
+
+const roleBindingName = service2.MonitoringName + "-role-binding"
+const roleBindingResourceName = "rolebindings"
+
+// roleBinding binds Prometheus service account to its role on each namespace listed on PrometheusServer rbac scope
+type roleBinding struct {
+	client   kubernetes.Interface
+	lister   listersV1.RoleBindingLister
+	name     string
+	selector labels.Set
+}
+
+// NewRoleBinding instantiates role binding resource enforcer, role bindings are only created on namespaces rbac scope
+func NewRoleBinding(cl kubernetes.Interface, l listersV1.RoleBindingLister) service2.ResourceEnforcer {
+	return &roleBinding{
+		client:   cl,
+		lister:   l,
+		name:     roleBindingName,
+		selector: labels.Set{"app": service2.MonitoringName, rbacLabel: roleBindingName},
+	}
+}
+
+// EnsureCreation checks role binding existence on each scope namespace, if it's not found it will create it
+func (c *roleBinding) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	for _, ns := range rbacNamespaces(obj) {
+		_, err := c.lister.RoleBindings(ns).Get(c.name)
+		if err == nil {
+			continue
+		}
+
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get role binding on namespace %s %w", ns, err)
+		}
+
+		if err := c.create(ctx, ns, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureDeletion removes all operator role bindings
+func (c *roleBinding) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	bindings, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list role bindings, error %w", err)
+	}
+
+	for _, b := range bindings {
+		log.Debugf("removing role binding %s on namespace %s", b.Name, b.Namespace)
+		err := c.client.RbacV1().RoleBindings(b.Namespace).Delete(ctx, b.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete role binding on namespace %s, error %w", b.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// IsCreated check if any operator role binding exists, role bindings are created all together on creation
+func (c *roleBinding) IsCreated() (bool, error) {
+	bindings, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return false, fmt.Errorf("unable to list role bindings %w", err)
+	}
+
+	return len(bindings) > 0, nil
+}
+
+// IsRequired checks if PrometheusServer is namespaces scoped
+func (c *roleBinding) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return rbacScope(obj) == v1alpha1.NamespacesScope
+}
+
+// Name returns resource enforcer target name
+func (c *roleBinding) Name() string {
+	return roleBindingResourceName
+}
+
+// IsDrifted checks if role bindings differ from PrometheusServer rbac scope namespaces or service account
+func (c *roleBinding) IsDrifted(obj *v1alpha1.PrometheusServer) (bool, error) {
+	bindings, err := c.lister.List(c.selector.AsSelector())
+	if err != nil {
+		return false, fmt.Errorf("unable to list role bindings %w", err)
+	}
+
+	var namespaces []string
+	for _, b := range bindings {
+		if len(b.Subjects) != 1 || b.Subjects[0].Name != serviceAccountName(obj) {
+			return true, nil
+		}
+		namespaces = append(namespaces, b.Namespace)
+	}
+	return namespacesDrifted(rbacNamespaces(obj), namespaces), nil
+}
+
+func (c *roleBinding) create(ctx context.Context, namespace string, obj *v1alpha1.PrometheusServer) error {
+	log.Debugf("creating role binding %s on namespace %s", c.name, namespace)
+	b := &rbac.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: namespace,
+			Labels:    c.selector,
+		},
+		RoleRef: rbac.RoleRef{
+			APIGroup: rbacApiGroup,
+			Kind:     "Role",
+			Name:     roleName,
+		},
+		Subjects: []rbac.Subject{
+			{
+				Kind:      serviceAccountKind,
+				Name:      serviceAccountName(obj),
+				Namespace: service2.MonitoringNamespace,
+			},
+		},
+	}
+
+	_, err := c.client.RbacV1().RoleBindings(namespace).Create(ctx, b, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create role binding on namespace %s, error %w", namespace, err)
+	}
+	return nil
+}
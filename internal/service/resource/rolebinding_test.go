@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesRoleBindingsOnEachScopeNamespaceOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().RoleBindings()
+
+	r := NewRoleBinding(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a", "team-b"}},
+		},
+	}
+	if err := r.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure role binding creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	for idx, ns := range []string{"team-a", "team-b"} {
+		action, ok := clActions[idx].(k8stest.CreateAction)
+		if !ok {
+			t.Fatalf("unexpected type got %T", clActions[idx])
+		}
+		if expected, got := ns, action.GetNamespace(); expected != got {
+			t.Errorf("unexpected namespace, expected %s got %s", expected, got)
+		}
+		b, ok := action.GetObject().(*rbac.RoleBinding)
+		if !ok {
+			t.Fatalf("unexpected type got %T", action.GetObject())
+		}
+		if expected, got := roleName, b.RoleRef.Name; expected != got {
+			t.Errorf("role ref does not match, expected %s got %s", expected, got)
+		}
+		if expected, got := "prometheus-server-default-foo", b.Subjects[0].Name; expected != got {
+			t.Errorf("subject does not match, expected %s got %s", expected, got)
+		}
+	}
+}
+
+func TestItRemovesRoleBindingsFromAllNamespacesOnDeletionRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().RoleBindings()
+
+	r := NewRoleBinding(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	for _, ns := range []string{"team-a", "removed-from-scope"} {
+		b := &rbac.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: roleBindingName, Namespace: ns, Labels: r.(*roleBinding).selector}}
+		if err := i.Informer().GetIndexer().Add(b); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a"}},
+		},
+	}
+	if err := r.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure role binding deletion, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	for _, action := range clActions {
+		if expected, got := "delete", action.GetVerb(); expected != got {
+			t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+		}
+	}
+}
+
+func TestItDetectsRoleBindingDriftFromScopeNamespacesAndSubject(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Rbac().V1().RoleBindings()
+
+	r := NewRoleBinding(clientSet, i.Lister()).(*roleBinding)
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			RBAC: &v1alpha1.RBACSpec{Scope: v1alpha1.NamespacesScope, Namespaces: []string{"team-a"}},
+		},
+	}
+	b := &rbac.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: roleBindingName, Namespace: "team-a", Labels: r.selector},
+		Subjects:   []rbac.Subject{{Kind: serviceAccountKind, Name: serviceAccountName(pm)}},
+	}
+	if err := i.Informer().GetIndexer().Add(b); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	drifted, err := r.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if drifted {
+		t.Fatal("role bindings on scope namespaces not expected as drifted")
+	}
+
+	pm.Spec.RBAC.Namespaces = append(pm.Spec.RBAC.Namespaces, "team-b")
+	if drifted, _ := r.IsDrifted(pm); !drifted {
+		t.Error("role bindings missing on added namespace expected as drifted")
+	}
+
+	pm.Spec.RBAC.Namespaces = []string{"team-a"}
+	pm.Spec.ServiceAccountName = "existing"
+	if drifted, _ := r.IsDrifted(pm); !drifted {
+		t.Error("role bindings with another subject expected as drifted")
+	}
+}
@@ -0,0 +1,50 @@
+package resource
+
+import (
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const colorLabel = "k8slab.info/color"
+
+// activeColor returns PrometheusServer running workload color, blue by default
+func activeColor(obj *v1alpha1.PrometheusServer) string {
+	if obj.Status.Color == "" {
+		return v1alpha1.BlueColor
+	}
+	return obj.Status.Color
+}
+
+// withColor returns a PrometheusServer copy whose active color is the given one
+func withColor(obj *v1alpha1.PrometheusServer, color string) *v1alpha1.PrometheusServer {
+	cp := obj.DeepCopy()
+	cp.Status.Color = color
+	return cp
+}
+
+// colorName suffixes resource name with color, blue resource names are kept unsuffixed
+func colorName(name, color string) string {
+	if color == v1alpha1.GreenColor {
+		return name + "-" + color
+	}
+	return name
+}
+
+// colorLabels returns shard resource labels on color
+func colorLabels(shard int32, color string) map[string]string {
+	l := shardLabels(shard)
+	l[colorLabel] = color
+	return l
+}
+
+// colorSelector matches shard resources on color, blue matches resources without color label too
+func colorSelector(color string) labels.Selector {
+	op, values := selection.In, []string{color}
+	if color == v1alpha1.BlueColor {
+		op, values = selection.NotIn, []string{v1alpha1.GreenColor}
+	}
+	// color label is a valid constant key, requirement never fails
+	r, _ := labels.NewRequirement(colorLabel, op, values)
+	return shardSelector().Add(*r)
+}
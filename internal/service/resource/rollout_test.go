@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesColoredStatefulSetOnRollout(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	sts := NewStatefulSet(clientSet, sif.Apps().V1().StatefulSets().Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Rollout = &v1alpha1.RolloutSpec{Strategy: v1alpha1.BlueGreenStrategy}
+	if err := sts.(service2.RolloutEnforcer).EnsureColorCreation(ctx, pm, v1alpha1.GreenColor); err != nil {
+		t.Fatalf("unable to ensure statefulset rollout, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	s, ok := clActions[0].(k8stest.CreateAction).GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	if expected, got := "prometheus-server-green", s.Name; expected != got {
+		t.Errorf("name does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := v1alpha1.GreenColor, s.Spec.Template.Labels[colorLabel]; expected != got {
+		t.Errorf("color label does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := "prometheus-server-config-green", s.Spec.Template.Spec.Volumes[0].ConfigMap.Name; expected != got {
+		t.Errorf("config volume does not match, expected %s got %s", expected, got)
+	}
+	if pm.Status.Color != "" {
+		t.Errorf("unexpected active color %s", pm.Status.Color)
+	}
+}
+
+func TestItChecksRolloutReadinessFromPodTargetsHealth(t *testing.T) {
+	health := "down"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"activeTargets":[{"scrapePool":"node","health":"` + health + `"}]}}`))
+	}))
+	defer srv.Close()
+
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pc := NewPodClients(sif.Core().V1().Secrets().Lister())
+	pc.address = func(pod string) string {
+		return strings.TrimPrefix(srv.URL, "http://")
+	}
+	sts := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, pc).(service2.RolloutEnforcer)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Rollout = &v1alpha1.RolloutSpec{Strategy: v1alpha1.BlueGreenStrategy}
+
+	s := &v1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-server-green", Namespace: service2.MonitoringNamespace, Labels: colorLabels(0, v1alpha1.GreenColor)},
+		Status:     v1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+	if err := i.Informer().GetIndexer().Add(s); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	ready, err := sts.IsColorReady(context.Background(), pm, v1alpha1.GreenColor)
+	if err != nil {
+		t.Fatalf("unexpected error checking rollout %v", err)
+	}
+	if ready {
+		t.Fatal("unexpected ready rollout with targets down")
+	}
+
+	health = "up"
+	ready, err = sts.IsColorReady(context.Background(), pm, v1alpha1.GreenColor)
+	if err != nil {
+		t.Fatalf("unexpected error checking rollout %v", err)
+	}
+	if !ready {
+		t.Fatal("expected ready rollout")
+	}
+
+	ready, err = sts.IsColorReady(context.Background(), pm, v1alpha1.BlueColor)
+	if err != nil {
+		t.Fatalf("unexpected error checking rollout %v", err)
+	}
+	if ready {
+		t.Fatal("unexpected ready rollout without statefulset")
+	}
+}
+
+func TestItRemovesOnlyColoredStatefulSetsOnRolloutDeletion(t *testing.T) {
+	blue := &v1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "prometheus-server", Namespace: service2.MonitoringNamespace, Labels: shardLabels(0)}}
+	green := &v1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "prometheus-server-green", Namespace: service2.MonitoringNamespace, Labels: colorLabels(0, v1alpha1.GreenColor)}}
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	for _, s := range []*v1.StatefulSet{blue, green} {
+		if err := i.Informer().GetIndexer().Add(s); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+	sts := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil).(service2.RolloutEnforcer)
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Rollout = &v1alpha1.RolloutSpec{Strategy: v1alpha1.BlueGreenStrategy}
+	if err := sts.EnsureColorDeletion(context.Background(), pm, v1alpha1.BlueColor); err != nil {
+		t.Fatalf("unable to ensure rollout deletion, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	if expected, got := "prometheus-server", clActions[0].(k8stest.DeleteAction).GetName(); expected != got {
+		t.Errorf("deleted name does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItSelectsActiveColorOnBlueGreenService(t *testing.T) {
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Rollout = &v1alpha1.RolloutSpec{Strategy: v1alpha1.BlueGreenStrategy}
+	pm.Status.Color = v1alpha1.GreenColor
+	s := (&service{}).build(pm)
+	if expected, got := v1alpha1.GreenColor, s.Spec.Selector[colorLabel]; expected != got {
+		t.Errorf("selector color does not match, expected %s got %s", expected, got)
+	}
+
+	pm.Spec.Rollout = nil
+	s = (&service{}).build(pm)
+	if _, ok := s.Spec.Selector[colorLabel]; ok {
+		t.Errorf("unexpected color selector %v", s.Spec.Selector)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -35,9 +36,9 @@ func NewService(cl kubernetes.Interface, l listersV1.ServiceLister) svc.Resource
 	}
 }
 
-// EnsureCreation checks service existence, if it's not found it will create it
+// EnsureCreation checks service existence, if it's not found it will create it, otherwise it's updated in place
 func (c *service) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
-	_, err := c.lister.Services(c.namespace).Get(c.name)
+	current, err := c.lister.Services(c.namespace).Get(c.name)
 	if apierrors.IsNotFound(err) {
 		return c.create(ctx, obj)
 	}
@@ -46,11 +47,15 @@ func (c *service) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusSe
 		return fmt.Errorf("unable to get service %w", err)
 	}
 
-	return nil
+	return c.update(ctx, current, obj)
 }
 
-// EnsureDeletion checks service existence, if it's it will delete it
+// EnsureDeletion removes service on PrometheusServer termination, service is kept on reloads
 func (c *service) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
 	log.Debugf("removing service  %s", c.name)
 	err := c.client.CoreV1().Services(c.namespace).Delete(ctx, c.name, metav1.DeleteOptions{})
 	if apierrors.IsNotFound(err) {
@@ -77,6 +82,11 @@ func (c *service) IsCreated() (bool, error) {
 	return true, nil
 }
 
+// UpdatesInPlace service is reconciled in place
+func (c *service) UpdatesInPlace() bool {
+	return true
+}
+
 // Name returns resource enforcer target name
 func (c *service) Name() string {
 	return serviceResourceName
@@ -84,30 +94,122 @@ func (c *service) Name() string {
 
 func (c *service) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
 	log.Debugf("creating service  %s", c.name)
+	_, err := c.client.CoreV1().Services(c.namespace).Create(ctx, c.build(obj), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create service, error %w", err)
+	}
+	return nil
+}
+
+// update applies desired service over the current one, cluster allocated values are kept
+func (c *service) update(ctx context.Context, current *corev1.Service, obj *v1alpha1.PrometheusServer) error {
+	desired := c.build(obj)
+	s := current.DeepCopy()
+	s.Labels = desired.Labels
+	s.Annotations = desired.Annotations
+	s.Spec.Selector = desired.Spec.Selector
+	s.Spec.Type = desired.Spec.Type
+	s.Spec.LoadBalancerSourceRanges = desired.Spec.LoadBalancerSourceRanges
+	s.Spec.SessionAffinity = desired.Spec.SessionAffinity
+	if desired.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		s.Spec.SessionAffinityConfig = nil
+	}
+
+	port := desired.Spec.Ports[0]
+	if port.NodePort == 0 && desired.Spec.Type != corev1.ServiceTypeClusterIP && len(current.Spec.Ports) > 0 {
+		port.NodePort = current.Spec.Ports[0].NodePort
+	}
+	s.Spec.Ports = []corev1.ServicePort{port}
+
+	if equality.Semantic.DeepEqual(current, s) {
+		return nil
+	}
+
+	log.Debugf("updating service  %s", c.name)
+	_, err := c.client.CoreV1().Services(c.namespace).Update(ctx, s, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to update service, error %w", err)
+	}
+	return nil
+}
+
+func (c *service) build(obj *v1alpha1.PrometheusServer) *corev1.Service {
+	spec := obj.Spec.Service
+	if spec == nil {
+		spec = &v1alpha1.ServiceSpec{}
+	}
+
+	serviceType := spec.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+
+	sessionAffinity := spec.SessionAffinity
+	if sessionAffinity == "" {
+		sessionAffinity = corev1.ServiceAffinityNone
+	}
+
+	annotations := map[string]string{
+		"prometheus.io/scrape": "true", // Prometheus service scrapped by itself
+		"prometheus.io/port":   fmt.Sprintf("%d", prometheusHttpPort),
+	}
+	if webTLS(obj) {
+		annotations["prometheus.io/scheme"] = "https"
+	}
+	if webBasicAuth(obj) {
+		// annotation discovery can not authenticate, Prometheus scrapes itself through its self scrape job
+		annotations["prometheus.io/scrape"] = "false"
+	}
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+
+	port := corev1.ServicePort{
+		Name:       "http",
+		Port:       servicePort(obj),
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromInt(prometheusHttpPort),
+	}
+	if serviceType != corev1.ServiceTypeClusterIP {
+		port.NodePort = spec.NodePort
+	}
+
+	// blue green rollouts switch traffic selecting active color pods
+	selector := map[string]string{"app": svc.MonitoringName}
+	if svc.IsBlueGreen(obj) {
+		selector[colorLabel] = activeColor(obj)
+	}
+
 	s := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      prometheusServiceName,
-			Namespace: svc.MonitoringNamespace,
-			Annotations: map[string]string{
-				"prometheus.io/scrape": "true", // Prometheus service scrapped by itself
-				"prometheus.io/port":   fmt.Sprintf("%d", prometheusHttpPort),
-			},
+			Name:        c.name,
+			Namespace:   c.namespace,
+			Labels:      spec.Labels,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{
-					Port:       prometheusServiceHttpPort,
-					Protocol:   corev1.ProtocolTCP,
-					TargetPort: intstr.FromInt(prometheusHttpPort),
-				},
-			},
-			Selector: map[string]string{"app": svc.MonitoringName},
-			Type:     corev1.ServiceTypeClusterIP,
+			Ports:           []corev1.ServicePort{port},
+			Selector:        selector,
+			Type:            serviceType,
+			SessionAffinity: sessionAffinity,
 		},
 	}
-	_, err := c.client.CoreV1().Services(c.namespace).Create(ctx, s, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("unable to create service, error %w", err)
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		s.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
 	}
-	return nil
+
+	return s
+}
+
+// serviceHost returns Prometheus Service DNS name
+func serviceHost() string {
+	return fmt.Sprintf("%s.%s.svc", prometheusServiceName, svc.MonitoringNamespace)
+}
+
+// servicePort returns Prometheus Service port, defaults to 8080
+func servicePort(obj *v1alpha1.PrometheusServer) int32 {
+	if obj.Spec.Service == nil || obj.Spec.Service.Port == 0 {
+		return prometheusServiceHttpPort
+	}
+	return obj.Spec.Service.Port
 }
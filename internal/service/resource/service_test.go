@@ -5,9 +5,14 @@ import (
 	"testing"
 	"time"
 
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
 )
 
 func TestItCreatesServiceOnCreationRequest(t *testing.T) {
@@ -48,7 +53,8 @@ func TestItRemovesServiceOnDeletionRequest(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancel()
 
-	pm := &v1alpha1.PrometheusServer{}
+	now := metav1.NewTime(time.Now())
+	pm := &v1alpha1.PrometheusServer{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}
 	if err := svc.EnsureDeletion(ctx, pm); err != nil {
 		t.Fatalf("unable to ensure service deletion, error %v", err)
 	}
@@ -62,3 +68,138 @@ func TestItRemovesServiceOnDeletionRequest(t *testing.T) {
 		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
 	}
 }
+
+func TestItKeepsServiceOnRemovalRequestWhileNotTerminating(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().Services()
+
+	svc := NewService(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := svc.EnsureDeletion(ctx, &v1alpha1.PrometheusServer{}); err != nil {
+		t.Fatalf("unable to ensure service deletion, error %v", err)
+	}
+	if expected, got := 0, len(clientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+}
+
+func TestItCreatesConfiguredServiceOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().Services()
+
+	svc := NewService(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Service: &v1alpha1.ServiceSpec{
+				Type:                     corev1.ServiceTypeLoadBalancer,
+				Port:                     80,
+				NodePort:                 30090,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+				Labels:                   map[string]string{"team": "observability"},
+				SessionAffinity:          corev1.ServiceAffinityClientIP,
+			},
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure service creation, error %v", err)
+	}
+
+	v, ok := clientSet.Actions()[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clientSet.Actions()[0])
+	}
+	s, ok := v.GetObject().(*corev1.Service)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	if expected, got := corev1.ServiceTypeLoadBalancer, s.Spec.Type; expected != got {
+		t.Errorf("service type does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := int32(80), s.Spec.Ports[0].Port; expected != got {
+		t.Errorf("port does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := int32(30090), s.Spec.Ports[0].NodePort; expected != got {
+		t.Errorf("node port does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 1, len(s.Spec.LoadBalancerSourceRanges); expected != got {
+		t.Errorf("source ranges do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "true", s.Annotations["prometheus.io/scrape"]; expected != got {
+		t.Errorf("scrape annotation does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := "true", s.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"]; expected != got {
+		t.Errorf("extra annotation does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := "observability", s.Labels["team"]; expected != got {
+		t.Errorf("label does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := corev1.ServiceAffinityClientIP, s.Spec.SessionAffinity; expected != got {
+		t.Errorf("session affinity does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItUpdatesServiceInPlaceKeepingAllocatedValues(t *testing.T) {
+	current := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: prometheusServiceName, Namespace: service2.MonitoringNamespace},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.96.0.10",
+			Type:      corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: prometheusServiceHttpPort, NodePort: 31000, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(prometheusHttpPort)},
+			},
+			Selector: map[string]string{"app": service2.MonitoringName},
+		},
+	}
+	clientSet := fake.NewSimpleClientset(current)
+
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().Services()
+	if err := i.Informer().GetIndexer().Add(current); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	svc := NewService(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Service: &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort, Port: 9091},
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure service creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	v, ok := clActions[0].(k8stest.UpdateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	s, ok := v.GetObject().(*corev1.Service)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+	if expected, got := current.Spec.ClusterIP, s.Spec.ClusterIP; expected != got {
+		t.Errorf("cluster ip does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := int32(9091), s.Spec.Ports[0].Port; expected != got {
+		t.Errorf("port does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := int32(31000), s.Spec.Ports[0].NodePort; expected != got {
+		t.Errorf("allocated node port does not match, expected %d got %d", expected, got)
+	}
+}
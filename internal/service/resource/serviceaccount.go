@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const serviceAccountResourceName = "serviceaccounts"
+const serviceAccountLabel = "k8slab.info/service-account"
+
+// serviceAccount takes care on PrometheusServer own service account
+type serviceAccount struct {
+	client    kubernetes.Interface
+	lister    listersV1.ServiceAccountLister
+	namespace string
+	selector  labels.Set
+}
+
+// NewServiceAccount instantiates prometheus service account resource enforcer
+func NewServiceAccount(cl kubernetes.Interface, l listersV1.ServiceAccountLister) service2.ResourceEnforcer {
+	return &serviceAccount{
+		client:    cl,
+		lister:    l,
+		namespace: service2.MonitoringNamespace,
+		selector:  labels.Set{"app": service2.MonitoringName, serviceAccountLabel: "true"},
+	}
+}
+
+// EnsureCreation checks PrometheusServer service account existence, if it's not found it will create it
+func (c *serviceAccount) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	_, err := c.lister.ServiceAccounts(c.namespace).Get(managedServiceAccountName(obj))
+	if apierrors.IsNotFound(err) {
+		return c.create(ctx, obj)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to get service account %w", err)
+	}
+
+	return nil
+}
+
+// EnsureDeletion removes PrometheusServer service account on its termination, account is kept on reloads
+func (c *serviceAccount) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	name := managedServiceAccountName(obj)
+	log.Debugf("removing service account %s", name)
+	err := c.client.CoreV1().ServiceAccounts(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to delete service account, error %w", err)
+	}
+	return nil
+}
+
+// IsCreated check if any operator managed service account exists
+func (c *serviceAccount) IsCreated() (bool, error) {
+	accounts, err := c.lister.ServiceAccounts(c.namespace).List(c.selector.AsSelector())
+	if err != nil {
+		return false, fmt.Errorf("unable to list service accounts %w", err)
+	}
+
+	return len(accounts) > 0, nil
+}
+
+// UpdatesInPlace service accounts are kept on reloads
+func (c *serviceAccount) UpdatesInPlace() bool {
+	return true
+}
+
+// IsRequired checks if PrometheusServer relies on operator managed service account
+func (c *serviceAccount) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.ServiceAccountName == ""
+}
+
+// Name returns resource enforcer target name
+func (c *serviceAccount) Name() string {
+	return serviceAccountResourceName
+}
+
+func (c *serviceAccount) create(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	name := managedServiceAccountName(obj)
+	log.Debugf("creating service account %s", name)
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+			Labels:    c.selector,
+		},
+	}
+
+	_, err := c.client.CoreV1().ServiceAccounts(c.namespace).Create(ctx, sa, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create service account, error %w", err)
+	}
+	return nil
+}
+
+// serviceAccountName returns the account Prometheus pods run as, the existing one when it's referenced
+func serviceAccountName(obj *v1alpha1.PrometheusServer) string {
+	if obj.Spec.ServiceAccountName != "" {
+		return obj.Spec.ServiceAccountName
+	}
+	return managedServiceAccountName(obj)
+}
+
+// managedServiceAccountName names PrometheusServer own account after its namespace and name
+func managedServiceAccountName(obj *v1alpha1.PrometheusServer) string {
+	return fmt.Sprintf("%s-%s-%s", service2.MonitoringName, obj.Namespace, obj.Name)
+}
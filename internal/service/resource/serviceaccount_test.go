@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesServiceAccountOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().ServiceAccounts()
+
+	sa := NewServiceAccount(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{}
+	if err := sa.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure service account creation, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	action := clActions[0]
+	if expected, got := "create", action.GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+	if expected, got := serviceAccountResourceName, action.GetResource().Resource; expected != got {
+		t.Fatalf("unexpected resource, expected %s got %s", expected, got)
+	}
+}
+
+func TestItDoesNotRequireServiceAccountWhenUsingAnExistingOne(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().ServiceAccounts()
+
+	sa := NewServiceAccount(clientSet, i.Lister()).(*serviceAccount)
+	if !sa.IsRequired(&v1alpha1.PrometheusServer{}) {
+		t.Error("service account expected as required")
+	}
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{ServiceAccountName: "existing"}}
+	if sa.IsRequired(pm) {
+		t.Error("service account not expected as required")
+	}
+	if expected, got := "existing", serviceAccountName(pm); expected != got {
+		t.Errorf("service account name does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItKeepsOtherPrometheusServersServiceAccounts(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Core().V1().ServiceAccounts()
+
+	sa := NewServiceAccount(clientSet, i.Lister())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+	if err := sa.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure service account deletion, error %v", err)
+	}
+	if expected, got := 0, len(clientSet.Actions()); expected != got {
+		t.Fatalf("service account expected to be kept on reloads, got %d actions", got)
+	}
+
+	now := metav1.Now()
+	pm.DeletionTimestamp = &now
+	if err := sa.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure service account deletion, error %v", err)
+	}
+	v, ok := clientSet.Actions()[0].(k8stest.DeleteAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clientSet.Actions()[0])
+	}
+	if expected, got := "prometheus-server-default-foo", v.GetName(); expected != got {
+		t.Errorf("deleted service account does not match, expected %s got %s", expected, got)
+	}
+}
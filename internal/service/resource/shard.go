@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const shardLabel = "k8slab.info/shard"
+const shardHashLabel = "__tmp_hash"
+
+// shards returns PrometheusServer shard workloads, defaults to 1
+func shards(obj *v1alpha1.PrometheusServer) int32 {
+	if obj.Spec.Shards == nil || *obj.Spec.Shards < 1 {
+		return 1
+	}
+	return *obj.Spec.Shards
+}
+
+// shardName keeps first shard resource names as unsharded ones
+func shardName(name string, shard int32) string {
+	if shard == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-shard-%d", name, shard)
+}
+
+func shardLabels(shard int32) map[string]string {
+	return map[string]string{"app": service2.MonitoringName, shardLabel: strconv.Itoa(int(shard))}
+}
+
+// shardSelector matches operator resources created per shard
+func shardSelector() labels.Selector {
+	// shard label is a valid constant key, requirement never fails
+	r, _ := labels.NewRequirement(shardLabel, selection.Exists, nil)
+	return labels.NewSelector().Add(*r)
+}
+
+// shardOf returns shard index from resource labels
+func shardOf(l map[string]string) (int32, bool) {
+	v, err := strconv.Atoi(l[shardLabel])
+	if err != nil {
+		return 0, false
+	}
+	return int32(v), true
+}
+
+// appendShardRelabelConfigs keeps shard targets by target address hash, unsharded PrometheusServer config is kept as is
+func appendShardRelabelConfigs(c *promconfig.Config, obj *v1alpha1.PrometheusServer, shard int32) {
+	total := shards(obj)
+	if total == 1 {
+		return
+	}
+
+	c.AppendRelabelConfigs(
+		relabelConfig(v1alpha1.RelabelConfig{
+			SourceLabels: []string{"__address__"},
+			Modulus:      uint64(total),
+			TargetLabel:  shardHashLabel,
+			Action:       "hashmod",
+		}),
+		relabelConfig(v1alpha1.RelabelConfig{
+			SourceLabels: []string{shardHashLabel},
+			Regex:        strconv.Itoa(int(shard)),
+			Action:       "keep",
+		}),
+	)
+}
@@ -0,0 +1,229 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesStatefulSetAndConfigMapPerShardOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+
+	shards := int32(3)
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1", Config: "scrape_configs: []\n", Shards: &shards},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	cm := NewConfigMap(clientSet, sif.Core().V1().ConfigMaps().Lister(), nil, ExternalLabels{}, nil)
+	if err := cm.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure configmap creation, error %v", err)
+	}
+	sts := NewStatefulSet(clientSet, sif.Apps().V1().StatefulSets().Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	if err := sts.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 6, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	expectedConfigMaps := []string{"prometheus-server-config", "prometheus-server-config-shard-1", "prometheus-server-config-shard-2"}
+	for idx, name := range expectedConfigMaps {
+		c := clActions[idx].(k8stest.CreateAction).GetObject().(*corev1.ConfigMap)
+		if expected, got := name, c.Name; expected != got {
+			t.Errorf("configmap name does not match, expected %s got %s", expected, got)
+		}
+	}
+
+	expectedStatefulSets := []string{"prometheus-server", "prometheus-server-shard-1", "prometheus-server-shard-2"}
+	for idx, name := range expectedStatefulSets {
+		s := clActions[idx+3].(k8stest.CreateAction).GetObject().(*v1.StatefulSet)
+		if expected, got := name, s.Name; expected != got {
+			t.Errorf("statefulset name does not match, expected %s got %s", expected, got)
+		}
+		if expected, got := expectedConfigMaps[idx], s.Spec.Template.Spec.Volumes[0].ConfigMap.Name; expected != got {
+			t.Errorf("config volume does not match, expected %s got %s", expected, got)
+		}
+		shard, ok := shardOf(s.Spec.Template.Labels)
+		if !ok || shard != int32(idx) {
+			t.Errorf("shard label does not match, expected %d got %d", idx, shard)
+		}
+	}
+}
+
+func TestItInjectsShardRelabelConfigAndExternalLabel(t *testing.T) {
+	shards := int32(2)
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version: "v2.35.0",
+			Config:  "scrape_configs:\n- job_name: foo\n",
+			Shards:  &shards,
+		},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 1)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: foo
+  relabel_configs:
+  - source_labels:
+    - __address__
+    target_label: __tmp_hash
+    modulus: 2
+    action: hashmod
+  - source_labels:
+    - __tmp_hash
+    regex: "1"
+    action: keep
+global:
+  external_labels:
+    prometheus_replica: ${POD_NAME}
+    prometheus_shard: "1"
+    prometheus: default/prometheus
+`
+	if expected != cf {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func TestItKeepsConfigUnshardedWithSingleShard(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1", Config: "scrape_configs:\n- job_name: foo\n"},
+	}
+
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+	if strings.Contains(cf, "hashmod") || strings.Contains(cf, shardExternalLabel) {
+		t.Errorf("unexpected shard config on unsharded config %s", cf)
+	}
+}
+
+func TestItReportsEachShardReplicasOnPrometheusServerStatus(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+
+	replicas := int32(2)
+	for shard, ready := range []int32{2, 1} {
+		st := &v1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      shardName(prometheusStatefulSetName, int32(shard)),
+				Namespace: service2.MonitoringNamespace,
+				Labels:    shardLabels(int32(shard)),
+			},
+			Spec:   v1.StatefulSetSpec{Replicas: &replicas},
+			Status: v1.StatefulSetStatus{ReadyReplicas: ready},
+		}
+		if err := i.Informer().GetIndexer().Add(st); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	svc := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	created, err := svc.IsCreated()
+	if err != nil {
+		t.Fatalf("unexpected error checking statefulset, error %v", err)
+	}
+	if created {
+		t.Error("shard with pending replicas not expected as created")
+	}
+
+	shards := int32(2)
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Replicas: &replicas, Shards: &shards}}
+	if err := svc.(service2.StatusReporter).ReportStatus(pm); err != nil {
+		t.Fatalf("unexpected error reporting status, error %v", err)
+	}
+	if expected, got := int32(4), pm.Status.Replicas; expected != got {
+		t.Errorf("replicas do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := int32(3), pm.Status.ReadyReplicas; expected != got {
+		t.Errorf("ready replicas do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 2, len(pm.Status.Shards); expected != got {
+		t.Fatalf("shard status do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := int32(1), pm.Status.Shards[1].ReadyReplicas; expected != got {
+		t.Errorf("shard ready replicas do not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItDetectsDriftOnShardsOutOfPrometheusServerShards(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1"}}
+	svc := &statefulSet{lister: i.Lister(), namespace: service2.MonitoringNamespace, name: prometheusStatefulSetName}
+	for shard := int32(0); shard < 2; shard++ {
+		st, err := svc.build(pm, shard)
+		if err != nil {
+			t.Fatalf("unexpected error building statefulset %v", err)
+		}
+		if err := i.Informer().GetIndexer().Add(st); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	drifted, err := svc.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if !drifted {
+		t.Error("expected drift on removed shard")
+	}
+}
+
+func TestItRemovesEveryShardStatefulSetOnRemovalRequest(t *testing.T) {
+	sts := []*v1.StatefulSet{
+		{ObjectMeta: metav1.ObjectMeta{Name: shardName(prometheusStatefulSetName, 0), Namespace: service2.MonitoringNamespace, Labels: shardLabels(0)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: shardName(prometheusStatefulSetName, 1), Namespace: service2.MonitoringNamespace, Labels: shardLabels(1)}},
+	}
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	for _, s := range sts {
+		if err := i.Informer().GetIndexer().Add(s); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+
+	svc := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := svc.EnsureDeletion(ctx, &v1alpha1.PrometheusServer{}); err != nil {
+		t.Fatalf("unable to ensure statefulset deletion, error %v", err)
+	}
+
+	var deleted []string
+	for _, a := range clientSet.Actions() {
+		if d, ok := a.(k8stest.DeleteAction); ok {
+			deleted = append(deleted, d.GetName())
+		}
+	}
+	for _, s := range sts {
+		if !contains(deleted, s.Name) {
+			t.Errorf("statefulset %s not deleted, deleted %v", s.Name, deleted)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestItSnapshotsReadyPodsAndReportsThemOnStatus(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"name":"20220510T101010Z-6a1b2c3d4e5f6a7b"}}`))
+	}))
+	defer srv.Close()
+
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	pods := sif.Core().V1().Pods()
+	for _, p := range []*corev1.Pod{snapshotPod("prometheus-server-1", corev1.ConditionTrue), snapshotPod("prometheus-server-0", corev1.ConditionFalse)} {
+		if err := pods.Informer().GetIndexer().Add(p); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+	pc := NewPodClients(sif.Core().V1().Secrets().Lister())
+	pc.address = func(pod string) string {
+		return strings.TrimPrefix(srv.URL, "http://")
+	}
+	sts := NewStatefulSet(clientSet, sif.Apps().V1().StatefulSets().Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), pods.Lister(), Image{}, pc).(service2.Snapshotter)
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: "v2.35.0", AdminAPI: &v1alpha1.AdminAPISpec{SnapshotBeforeChange: true}}}
+	if err := sts.Snapshot(context.Background(), pm); err != nil {
+		t.Fatalf("unexpected error taking snapshots %v", err)
+	}
+
+	if expected, got := 1, len(requested); expected != got {
+		t.Fatalf("total requests do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 1, len(pm.Status.Snapshots); expected != got {
+		t.Fatalf("snapshots do not match, expected %d got %d", expected, got)
+	}
+	if s := pm.Status.Snapshots[0]; s.Pod != "prometheus-server-1" || s.Name != "20220510T101010Z-6a1b2c3d4e5f6a7b" {
+		t.Errorf("unexpected snapshot %v", s)
+	}
+	if !contains(prometheusArgs(pm), "--web.enable-admin-api") {
+		t.Errorf("admin api arg not found on %v", prometheusArgs(pm))
+	}
+}
+
+func TestItReloadsReadyPodsConfig(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.Method+" "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	pods := sif.Core().V1().Pods()
+	for _, p := range []*corev1.Pod{snapshotPod("prometheus-server-1", corev1.ConditionTrue), snapshotPod("prometheus-server-0", corev1.ConditionFalse)} {
+		if err := pods.Informer().GetIndexer().Add(p); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+	pc := NewPodClients(sif.Core().V1().Secrets().Lister())
+	pc.address = func(pod string) string {
+		return strings.TrimPrefix(srv.URL, "http://")
+	}
+	sts := NewStatefulSet(clientSet, sif.Apps().V1().StatefulSets().Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), pods.Lister(), Image{}, pc).(service2.ConfigReloader)
+
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: "v2.35.0", AdminAPI: &v1alpha1.AdminAPISpec{}}}
+	if err := sts.Reload(context.Background(), pm); err != nil {
+		t.Fatalf("unexpected error reloading %v", err)
+	}
+
+	if expected, got := 1, len(requested); expected != got {
+		t.Fatalf("total requests do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "POST /-/reload", requested[0]; expected != got {
+		t.Errorf("request does not match, expected %s got %s", expected, got)
+	}
+	if !contains(prometheusArgs(pm), "--web.enable-lifecycle") {
+		t.Errorf("lifecycle arg not found on %v", prometheusArgs(pm))
+	}
+}
+
+func snapshotPod(name string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: service2.MonitoringNamespace, Labels: shardLabels(0)},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+	}
+}
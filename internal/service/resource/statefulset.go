@@ -0,0 +1,748 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/apps/v1"
+	coreListersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const prometheusStatefulSetName = service2.MonitoringName
+const statefulSetResourceName = "statefulsets"
+const prometheusHttpPort = 9090
+const prometheusServiceHttpPort = 8080
+const prometheusConfigPath = "/etc/prometheus/"
+const prometheusStoragePath = "/prometheus/"
+const prometheusStorageVolumeName = "prometheus-storage-volume"
+const prometheusConfigVolumeName = "prometheus-config-volume"
+const prometheusReadinessEndpoint = "/-/ready"
+const prometheusLivenessEndpoint = "/-/healthy"
+const defaultInitialDelaySeconds = 2
+const defaultTimeoutSeconds = 5
+const prometheusUserID = 65534 // official Prometheus image runs as nobody
+const podNameEnv = "POD_NAME"
+const configCheckContainerName = "config-check"
+const promtoolPath = "/bin/promtool"
+const configLoadError = "error loading config"
+const prometheusAdminAPIArg = "--web.enable-admin-api"
+const prometheusLifecycleArg = "--web.enable-lifecycle"
+const restartedAtAnnotation = "k8slab.info/restarted-at"
+const podTemplateHashAnnotation = "k8slab.info/pod-template-hash"
+const podTemplateHashLength = 16
+
+var prometheusConfigFileArg = fmt.Sprintf("--config.file=%sprometheus.yml", prometheusConfigPath)
+var prometheusDbPathArg = fmt.Sprintf("--storage.tsdb.path=%s", prometheusStoragePath)
+
+type statefulSet struct {
+	client    kubernetes.Interface
+	lister    listersV1.StatefulSetLister
+	storage   *volumeClaim
+	pods      coreListersV1.PodLister
+	image     Image
+	clients   *PodClients
+	namespace string
+	name      string
+}
+
+// NewStatefulSet instantiates prometheus statefulset resource enforcer
+func NewStatefulSet(cl kubernetes.Interface, l listersV1.StatefulSetLister, pvc coreListersV1.PersistentVolumeClaimLister, pl coreListersV1.PodLister, img Image, pc *PodClients) service2.ResourceEnforcer {
+	return &statefulSet{
+		client:    cl,
+		lister:    l,
+		storage:   newVolumeClaim(cl, pvc),
+		pods:      pl,
+		image:     img,
+		clients:   pc,
+		namespace: service2.MonitoringNamespace,
+		name:      prometheusStatefulSetName,
+	}
+}
+
+// EnsureCreation checks each shard statefulset existence on active color, if it's not found it will create it
+func (c *statefulSet) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	for shard := int32(0); shard < shards(obj); shard++ {
+		name := shardName(colorName(c.name, activeColor(obj)), shard)
+		_, err := c.lister.StatefulSets(c.namespace).Get(name)
+		if err == nil {
+			continue
+		}
+
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get statefulset %s %w", name, err)
+		}
+
+		if err := c.create(ctx, obj, shard); err != nil {
+			return err
+		}
+	}
+
+	return c.storage.update(ctx, obj)
+}
+
+// EnsureDeletion removes all shard statefulsets, volume claims are removed on termination by reclaim policy
+func (c *statefulSet) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	sets, err := c.lister.StatefulSets(c.namespace).List(shardSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list statefulsets, error %w", err)
+	}
+
+	names := []string{c.name}
+	for _, s := range sets {
+		if s.Name != c.name {
+			names = append(names, s.Name)
+		}
+	}
+
+	for _, name := range names {
+		log.Debugf("removing statefulset  %s", name)
+		err := c.client.AppsV1().StatefulSets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete statefulset %s, error %w", name, err)
+		}
+	}
+
+	return c.storage.ensureDeletion(ctx, obj)
+}
+
+// IsCreated check if shard statefulsets exist and all their replicas are ready
+func (c *statefulSet) IsCreated() (bool, error) {
+	sets, err := c.list("")
+	if err != nil {
+		return false, err
+	}
+
+	for _, s := range sets {
+		if s.Status.ReadyReplicas < desiredReplicas(s) {
+			return false, nil
+		}
+	}
+
+	return len(sets) > 0, nil
+}
+
+// ReportStatus updates PrometheusServer status with each shard statefulset desired and ready replicas
+func (c *statefulSet) ReportStatus(obj *v1alpha1.PrometheusServer) error {
+	obj.Status.Replicas = 0
+	obj.Status.ReadyReplicas = 0
+	obj.Status.Image = ""
+	obj.Status.Shards = nil
+	for shard := int32(0); shard < shards(obj); shard++ {
+		st := v1alpha1.ShardStatus{Shard: shard, Replicas: replicas(obj)}
+		s, err := c.lister.StatefulSets(c.namespace).Get(shardName(colorName(c.name, activeColor(obj)), shard))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get statefulset %w", err)
+		}
+
+		if err == nil {
+			st.Replicas = desiredReplicas(s)
+			st.ReadyReplicas = s.Status.ReadyReplicas
+			if shard == 0 {
+				obj.Status.Image = runningImage(s)
+			}
+		}
+
+		obj.Status.Replicas += st.Replicas
+		obj.Status.ReadyReplicas += st.ReadyReplicas
+		obj.Status.Shards = append(obj.Status.Shards, st)
+	}
+
+	return nil
+}
+
+// Name returns resource enforcer target name
+func (c *statefulSet) Name() string {
+	return statefulSetResourceName
+}
+
+// IsDrifted checks if active color statefulsets differ from the ones required by PrometheusServer
+func (c *statefulSet) IsDrifted(obj *v1alpha1.PrometheusServer) (bool, error) {
+	sets, err := c.list(activeColor(obj))
+	if err != nil {
+		return false, err
+	}
+
+	for _, s := range sets {
+		shard, _ := shardOf(s.Labels)
+		if shard >= shards(obj) || desiredReplicas(s) != replicas(obj) {
+			return true, nil
+		}
+
+		desired, err := c.build(obj, shard)
+		if err != nil {
+			return false, err
+		}
+		if h, ok := s.Annotations[podTemplateHashAnnotation]; ok && h != desired.Annotations[podTemplateHashAnnotation] {
+			return true, nil
+		}
+		if podSpecDrifted(&desired.Spec.Template.Spec, &s.Spec.Template.Spec) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Restart rolls active color shard statefulsets pods setting restarted at pod template annotation
+func (c *statefulSet) Restart(ctx context.Context, obj *v1alpha1.PrometheusServer, at string) error {
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	patch.Spec.Template.Metadata.Annotations = map[string]string{restartedAtAnnotation: at}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("unable to marshal restart patch, error %w", err)
+	}
+
+	sets, err := c.list(activeColor(obj))
+	if err != nil {
+		return err
+	}
+	for _, s := range sets {
+		log.Debugf("restarting statefulset  %s", s.Name)
+		_, err := c.client.AppsV1().StatefulSets(c.namespace).Patch(ctx, s.Name, types.StrategicMergePatchType, raw, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to restart statefulset %s, error %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// Failure checks active color pods for config load errors
+func (c *statefulSet) Failure(obj *v1alpha1.PrometheusServer) (string, error) {
+	pods, err := c.pods.Pods(c.namespace).List(colorSelector(activeColor(obj)))
+	if err != nil {
+		return "", fmt.Errorf("unable to list pods %w", err)
+	}
+
+	for _, p := range pods {
+		for _, s := range p.Status.InitContainerStatuses {
+			if t := terminated(s); s.Name == configCheckContainerName && t != nil && t.ExitCode != 0 {
+				return fmt.Sprintf("pod %s config check failed: %s", p.Name, strings.TrimSpace(t.Message)), nil
+			}
+		}
+		for _, s := range p.Status.ContainerStatuses {
+			if t := terminated(s); s.Name == service2.MonitoringName && t != nil && strings.Contains(strings.ToLower(t.Message), configLoadError) {
+				return fmt.Sprintf("pod %s config load failed: %s", p.Name, configLoadLine(t.Message)), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Snapshot takes a TSDB snapshot from each ready active color pod
+func (c *statefulSet) Snapshot(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	pods, err := c.pods.Pods(c.namespace).List(colorSelector(activeColor(obj)))
+	if err != nil {
+		return fmt.Errorf("unable to list pods %w", err)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	var snapshots []v1alpha1.SnapshotStatus
+	var errs []string
+	for _, p := range pods {
+		if !podReady(p) {
+			continue
+		}
+		name, err := c.clients.snapshot(ctx, obj, p.Name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("pod %s %v", p.Name, err))
+			continue
+		}
+		snapshots = append(snapshots, v1alpha1.SnapshotStatus{Pod: p.Name, Name: name, Timestamp: metav1.Now()})
+	}
+	obj.Status.Snapshots = snapshots
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to take snapshots, %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Reload requests config reload on each ready active color pod
+func (c *statefulSet) Reload(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	pods, err := c.pods.Pods(c.namespace).List(colorSelector(activeColor(obj)))
+	if err != nil {
+		return fmt.Errorf("unable to list pods %w", err)
+	}
+
+	var errs []string
+	for _, p := range pods {
+		if !podReady(p) {
+			continue
+		}
+		if err := c.clients.reload(ctx, obj, p.Name); err != nil {
+			errs = append(errs, fmt.Sprintf("pod %s %v", p.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("unable to reload, %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (c *statefulSet) create(ctx context.Context, obj *v1alpha1.PrometheusServer, shard int32) error {
+	s, err := c.build(obj, shard)
+	if err != nil {
+		return err
+	}
+	log.Debugf("creating statefulset  %s", s.Name)
+	_, err = c.client.AppsV1().StatefulSets(c.namespace).Create(ctx, s, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create statefulset, error %w", err)
+	}
+	return nil
+}
+
+// EnsureColorCreation creates shard statefulsets on color
+func (c *statefulSet) EnsureColorCreation(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error {
+	return c.EnsureCreation(ctx, withColor(obj, color))
+}
+
+// IsColorReady checks all shard statefulsets on color have their replicas ready, and each pod scrapes healthy targets
+func (c *statefulSet) IsColorReady(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) (bool, error) {
+	for shard := int32(0); shard < shards(obj); shard++ {
+		name := shardName(colorName(c.name, color), shard)
+		s, err := c.lister.StatefulSets(c.namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to get statefulset %s %w", name, err)
+		}
+		if s.Status.ReadyReplicas < replicas(obj) {
+			return false, nil
+		}
+
+		for i := int32(0); i < replicas(obj); i++ {
+			pod := fmt.Sprintf("%s-%d", name, i)
+			healthy, err := c.clients.healthy(ctx, obj, pod)
+			if err != nil {
+				log.Infof("unable to check pod %s targets health, error %v", pod, err)
+				return false, nil
+			}
+			if !healthy {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// EnsureColorDeletion removes shard statefulsets on color, storage volume claims are kept
+func (c *statefulSet) EnsureColorDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error {
+	sets, err := c.list(color)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sets {
+		log.Debugf("removing statefulset  %s", s.Name)
+		err := c.client.AppsV1().StatefulSets(c.namespace).Delete(ctx, s.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete statefulset %s, error %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// list returns shard statefulsets on color, all colors when it's empty
+func (c *statefulSet) list(color string) ([]*appsv1.StatefulSet, error) {
+	selector := shardSelector()
+	if color != "" {
+		selector = colorSelector(color)
+	}
+	sets, err := c.lister.StatefulSets(c.namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list statefulsets %w", err)
+	}
+	if color == v1alpha1.GreenColor {
+		return sets, nil
+	}
+
+	for _, s := range sets {
+		if s.Name == c.name {
+			return sets, nil
+		}
+	}
+
+	s, err := c.lister.StatefulSets(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) {
+		return sets, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to get statefulset %w", err)
+	}
+
+	return append(sets, s), nil
+}
+
+// build returns shard statefulset on active color, each shard mounts its own config
+func (c *statefulSet) build(obj *v1alpha1.PrometheusServer, shard int32) (*appsv1.StatefulSet, error) {
+	authorization, err := c.probeAuthorization(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := replicas(obj)
+	defaultPermission := int32(420)
+	color := activeColor(obj)
+	labels := colorLabels(shard, color)
+	volumes := []corev1.Volume{
+		{
+			Name: prometheusConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: shardName(colorName(prometheusConfigMapName, color), shard)},
+					DefaultMode:          &defaultPermission,
+				},
+			},
+		},
+	}
+	var claims []corev1.PersistentVolumeClaim
+	switch {
+	case isAgent(obj):
+		// agent keeps its write ahead log on image working directory, no TSDB storage is required
+	case obj.Spec.Storage == nil:
+		volumes = append(volumes, corev1.Volume{
+			Name:         prometheusStorageVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	default:
+		claims = append(claims, c.storage.template(obj))
+	}
+	volumes = append(volumes, secretVolumes(obj)...)
+	volumes = append(volumes, webVolumes(obj)...)
+	volumes = append(volumes, obj.Spec.Volumes...)
+
+	var initContainers []corev1.Container
+	if obj.Spec.ValidateConfig {
+		initContainers = append(initContainers, c.configCheckContainer(obj))
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: labels,
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: serviceAccountName(obj),
+			SecurityContext:    podSecurityContext(obj),
+			NodeSelector:       obj.Spec.NodeSelector,
+			Tolerations:        obj.Spec.Tolerations,
+			Affinity:           obj.Spec.Affinity,
+			PriorityClassName:  obj.Spec.PriorityClassName,
+			ImagePullSecrets:   c.image.pullSecrets(obj),
+			InitContainers:     initContainers,
+			Containers:         append([]corev1.Container{c.container(obj, authorization)}, obj.Spec.Sidecars...),
+			Volumes:            volumes,
+		},
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        shardName(colorName(c.name, color), shard),
+			Namespace:   c.namespace,
+			Labels:      labels,
+			Annotations: map[string]string{podTemplateHashAnnotation: podTemplateHash(template)},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         prometheusHeadlessServiceName,
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template:             template,
+			VolumeClaimTemplates: claims,
+		},
+	}, nil
+}
+
+// probeAuthorization returns probes self scrape user Authorization header on basic auth protected Prometheus
+func (c *statefulSet) probeAuthorization(obj *v1alpha1.PrometheusServer) (string, error) {
+	if !webBasicAuth(obj) {
+		return "", nil
+	}
+	return c.clients.selfScrapeAuthorization()
+}
+
+// podTemplateHash identifies desired pod template, api server defaulted fields are not included as it's built ones
+func podTemplateHash(t corev1.PodTemplateSpec) string {
+	// pod templates are plain api types, marshalling never fails
+	raw, _ := json.Marshal(t)
+	h := sha256.Sum256(raw)
+	return hex.EncodeToString(h[:])[:podTemplateHashLength]
+}
+
+func (c *statefulSet) container(obj *v1alpha1.PrometheusServer, authorization string) corev1.Container {
+	return corev1.Container{
+		Name:            service2.MonitoringName,
+		Image:           c.image.name(obj),
+		ImagePullPolicy: c.image.pullPolicy(obj),
+		Args:            prometheusArgs(obj),
+		Resources:       obj.Spec.Resources,
+		SecurityContext: containerSecurityContext(obj),
+		Env: []corev1.EnvVar{
+			{
+				Name: podNameEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				},
+			},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: prometheusHttpPort,
+			},
+		},
+		VolumeMounts: volumeMounts(obj),
+		// Prometheus logs on termination message, config load errors are read from it
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler:        probeHandler(obj, prometheusLivenessEndpoint, authorization),
+			InitialDelaySeconds: defaultInitialDelaySeconds,
+			TimeoutSeconds:      defaultTimeoutSeconds,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler:        probeHandler(obj, prometheusReadinessEndpoint, authorization),
+			InitialDelaySeconds: defaultInitialDelaySeconds,
+			TimeoutSeconds:      defaultTimeoutSeconds,
+		},
+	}
+}
+
+// probeHandler checks Prometheus endpoint over its web scheme, with authorization header when it's defined
+func probeHandler(obj *v1alpha1.PrometheusServer, endpoint, authorization string) corev1.ProbeHandler {
+	var headers []corev1.HTTPHeader
+	if authorization != "" {
+		headers = append(headers, corev1.HTTPHeader{Name: "Authorization", Value: authorization})
+	}
+
+	return corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+		Path:        path.Join(routePrefix(obj), endpoint),
+		Port:        intstr.FromInt(prometheusHttpPort),
+		Scheme:      webScheme(obj),
+		HTTPHeaders: headers,
+	}}
+}
+
+// configCheckContainer validates mounted Prometheus config with promtool, pod does not start on invalid config
+func (c *statefulSet) configCheckContainer(obj *v1alpha1.PrometheusServer) corev1.Container {
+	return corev1.Container{
+		Name:                     configCheckContainerName,
+		Image:                    c.image.name(obj),
+		ImagePullPolicy:          c.image.pullPolicy(obj),
+		Command:                  []string{promtoolPath},
+		Args:                     []string{"check", "config", fmt.Sprintf("%sprometheus.yml", prometheusConfigPath)},
+		SecurityContext:          containerSecurityContext(obj),
+		VolumeMounts:             volumeMounts(obj),
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
+// volumeMounts returns Prometheus config, storage and remote endpoint secret mounts followed by PrometheusServer extra mounts
+func volumeMounts(obj *v1alpha1.PrometheusServer) []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      prometheusConfigVolumeName,
+			MountPath: prometheusConfigPath,
+		},
+	}
+	if !isAgent(obj) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      prometheusStorageVolumeName,
+			MountPath: prometheusStoragePath,
+		})
+	}
+	mounts = append(mounts, secretVolumeMounts(obj)...)
+	mounts = append(mounts, webVolumeMounts(obj)...)
+	return append(mounts, obj.Spec.VolumeMounts...)
+}
+
+func prometheusArgs(obj *v1alpha1.PrometheusServer) []string {
+	if isAgent(obj) {
+		args := append([]string{prometheusConfigFileArg}, expandExternalLabelsFeature.args(obj)...)
+		args = append(args, agentModeArg(obj))
+		return append(args, webArgs(obj)...)
+	}
+
+	args := append([]string{prometheusConfigFileArg, prometheusDbPathArg}, expandExternalLabelsFeature.args(obj)...)
+	if obj.Spec.Retention != "" {
+		args = append(args, fmt.Sprintf("--storage.tsdb.retention.time=%s", obj.Spec.Retention))
+	}
+	if obj.Spec.RetentionSize != "" {
+		args = append(args, fmt.Sprintf("--storage.tsdb.retention.size=%s", obj.Spec.RetentionSize))
+	}
+	if obj.Spec.AdminAPI != nil {
+		args = append(args, prometheusAdminAPIArg)
+	}
+
+	return append(args, webArgs(obj)...)
+}
+
+// webArgs configures Prometheus external url, route prefix, web config file and lifecycle API
+func webArgs(obj *v1alpha1.PrometheusServer) []string {
+	var args []string
+	if u := externalURL(obj); u != "" {
+		args = append(args, fmt.Sprintf("--web.external-url=%s", u), fmt.Sprintf("--web.route-prefix=%s", routePrefix(obj)))
+	}
+	if webSecured(obj) {
+		args = append(args, fmt.Sprintf("--web.config.file=%s", path.Join(webConfigPath, webConfigKey)))
+	}
+	if obj.Spec.AdminAPI != nil {
+		args = append(args, prometheusLifecycleArg)
+	}
+	return args
+}
+
+// podSecurityContext defaults to run as Prometheus image user, its group owns mounted volumes
+func podSecurityContext(obj *v1alpha1.PrometheusServer) *corev1.PodSecurityContext {
+	if obj.Spec.SecurityContext != nil {
+		return obj.Spec.SecurityContext
+	}
+
+	runAsNonRoot := true
+	id := int64(prometheusUserID)
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    &id,
+		RunAsGroup:   &id,
+		FSGroup:      &id,
+	}
+}
+
+func containerSecurityContext(obj *v1alpha1.PrometheusServer) *corev1.SecurityContext {
+	if obj.Spec.ContainerSecurityContext != nil {
+		return obj.Spec.ContainerSecurityContext
+	}
+
+	allowPrivilegeEscalation := false
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+}
+
+// podSpecDrifted compares operator managed pod spec fields, api server defaulted ones are ignored
+func podSpecDrifted(desired, current *corev1.PodSpec) bool {
+	if !equality.Semantic.DeepEqual(desired.NodeSelector, current.NodeSelector) ||
+		!equality.Semantic.DeepEqual(desired.Tolerations, current.Tolerations) ||
+		!equality.Semantic.DeepEqual(desired.Affinity, current.Affinity) ||
+		!equality.Semantic.DeepEqual(desired.SecurityContext, current.SecurityContext) ||
+		!equality.Semantic.DeepEqual(desired.ImagePullSecrets, current.ImagePullSecrets) ||
+		desired.ServiceAccountName != current.ServiceAccountName ||
+		desired.PriorityClassName != current.PriorityClassName {
+		return true
+	}
+
+	return containersDrifted(desired.InitContainers, current.InitContainers) ||
+		containersDrifted(desired.Containers, current.Containers) ||
+		!equality.Semantic.DeepEqual(volumeNames(desired.Volumes), volumeNames(current.Volumes))
+}
+
+func containersDrifted(desired, current []corev1.Container) bool {
+	if len(desired) != len(current) {
+		return true
+	}
+
+	for i := range desired {
+		d, c := desired[i], current[i]
+		if d.Name != c.Name ||
+			d.Image != c.Image ||
+			(d.ImagePullPolicy != "" && d.ImagePullPolicy != c.ImagePullPolicy) ||
+			!equality.Semantic.DeepEqual(d.Command, c.Command) ||
+			!equality.Semantic.DeepEqual(d.Args, c.Args) ||
+			!equality.Semantic.DeepEqual(d.Resources, c.Resources) ||
+			!equality.Semantic.DeepEqual(d.SecurityContext, c.SecurityContext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func replicas(obj *v1alpha1.PrometheusServer) int32 {
+	if obj.Spec.Replicas == nil {
+		return 1
+	}
+	return *obj.Spec.Replicas
+}
+
+func desiredReplicas(s *appsv1.StatefulSet) int32 {
+	if s.Spec.Replicas == nil {
+		return 1
+	}
+	return *s.Spec.Replicas
+}
+
+func volumeNames(volumes []corev1.Volume) []string {
+	var res []string
+	for _, v := range volumes {
+		res = append(res, v.Name)
+	}
+	return res
+}
+
+func runningImage(s *appsv1.StatefulSet) string {
+	for _, c := range s.Spec.Template.Spec.Containers {
+		if c.Name == service2.MonitoringName {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// terminated returns container current or last termination state, nil when it never terminated
+func terminated(s corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+	if s.State.Terminated != nil {
+		return s.State.Terminated
+	}
+	return s.LastTerminationState.Terminated
+}
+
+// configLoadLine returns termination message line reporting config load error, Prometheus logs it right before exit
+func configLoadLine(msg string) string {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(lines[i]), configLoadError) {
+			return lines[i]
+		}
+	}
+	return msg
+}
+
+// podReady checks pod Ready condition
+func podReady(p *corev1.Pod) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,662 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesStatefulSetOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	version := "v1.0.1"
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: version},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	action := clActions[0]
+	if expected, got := "create", action.GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+	if expected, got := statefulSetResourceName, action.GetResource().Resource; expected != got {
+		t.Fatalf("unexpected resource, expected %s got %s", expected, got)
+	}
+
+	v, ok := action.(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", action)
+	}
+
+	d, ok := v.GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	if expected, got := "prom/prometheus:"+version, d.Spec.Template.Spec.Containers[0].Image; expected != got {
+		t.Fatalf("image version do not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRemovesStatefulSetOnRemovalRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{}
+	if err := svc.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset deletion, error %v", err)
+	}
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	action := clActions[0]
+	if expected, got := "delete", action.GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+}
+
+func TestItCreatesStatefulSetWithStorageClaimTemplateOnCreationRequestWithStorage(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	replicas := int32(2)
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version:  "v1.0.1",
+			Replicas: &replicas,
+			Storage:  &v1alpha1.StorageSpec{Size: resource.MustParse("10Gi")},
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	v, ok := clActions[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+
+	d, ok := v.GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	if expected, got := replicas, *d.Spec.Replicas; expected != got {
+		t.Errorf("replicas do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 1, len(d.Spec.VolumeClaimTemplates); expected != got {
+		t.Fatalf("claim templates do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := prometheusStorageVolumeName, d.Spec.VolumeClaimTemplates[0].Name; expected != got {
+		t.Errorf("claim template name does not match, expected %s got %s", expected, got)
+	}
+	for _, vol := range d.Spec.Template.Spec.Volumes {
+		if vol.Name == prometheusStorageVolumeName {
+			t.Error("storage volume must be provided by claim template")
+		}
+	}
+}
+
+func TestItKeepsStorageClaimOnRemovalRequestWhileNotTerminating(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Storage: &v1alpha1.StorageSpec{Size: resource.MustParse("10Gi"), ReclaimPolicy: v1alpha1.StorageDelete},
+		},
+	}
+	if err := svc.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset deletion, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	if expected, got := statefulSetResourceName, clActions[0].GetResource().Resource; expected != got {
+		t.Fatalf("unexpected resource, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRemovesStorageClaimOnTerminationWithDeleteReclaimPolicy(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prometheusStorageVolumeName + "-" + prometheusStatefulSetName + "-0",
+			Namespace: service2.MonitoringNamespace,
+			Labels:    map[string]string{"app": service2.MonitoringName, storageLabel: prometheusStorageVolumeName},
+		},
+	}
+	if err := pvc.Informer().GetIndexer().Add(claim); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	now := metav1.NewTime(time.Now())
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+		Spec: v1alpha1.PrometheusServerSpec{
+			Storage: &v1alpha1.StorageSpec{Size: resource.MustParse("10Gi"), ReclaimPolicy: v1alpha1.StorageDelete},
+		},
+	}
+	if err := svc.EnsureDeletion(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset deletion, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 2, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	action := clActions[1]
+	if expected, got := "delete", action.GetVerb(); expected != got {
+		t.Fatalf("unexpected verb, expected %s got %s", expected, got)
+	}
+	if expected, got := "persistentvolumeclaims", action.GetResource().Resource; expected != got {
+		t.Fatalf("unexpected resource, expected %s got %s", expected, got)
+	}
+}
+
+func TestItExpandsRetainedStorageClaimsWhenStorageClassAllowsIt(t *testing.T) {
+	expandable, fixed := true, false
+	claims := []*corev1.PersistentVolumeClaim{storageClaim("fast", "10Gi"), storageClaim("slow", "10Gi")}
+	clientSet := fake.NewSimpleClientset(
+		claims[0], claims[1],
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}, AllowVolumeExpansion: &expandable},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}, AllowVolumeExpansion: &fixed},
+	)
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+	st := newVolumeClaim(clientSet, pvc.Lister())
+
+	for _, claim := range claims {
+		class := claim.Name
+		pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{
+			Storage: &v1alpha1.StorageSpec{StorageClassName: &class, Size: resource.MustParse("20Gi")},
+		}}
+		if err := pvc.Informer().GetIndexer().Replace([]interface{}{claim}, ""); err != nil {
+			t.Fatalf("unable to replace indexer entries %v", err)
+		}
+		if err := st.update(context.Background(), pm); err != nil {
+			t.Fatalf("unexpected error updating storage claims %v", err)
+		}
+
+		res, err := clientSet.CoreV1().PersistentVolumeClaims(service2.MonitoringNamespace).Get(context.Background(), class, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error getting claim %v", err)
+		}
+		size := res.Spec.Resources.Requests[corev1.ResourceStorage]
+		if expected, got := class == "fast", size.String() == "20Gi"; expected != got {
+			t.Errorf("claim %s expansion does not match, expected %t got size %s", class, expected, size.String())
+		}
+		if expected, got := class == "fast", meta.IsStatusConditionTrue(pm.Status.Conditions, v1alpha1.StorageSynced); expected != got {
+			t.Errorf("claim %s storage synced does not match, expected %t got %t", class, expected, got)
+		}
+	}
+}
+
+func storageClaim(class, size string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      class,
+			Namespace: service2.MonitoringNamespace,
+			Labels:    map[string]string{"app": service2.MonitoringName, storageLabel: prometheusStorageVolumeName},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &class,
+			Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+		},
+	}
+}
+
+func TestItReportsStatefulSetReplicasOnPrometheusServerStatus(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	replicas := int32(2)
+	st := &v1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: prometheusStatefulSetName, Namespace: service2.MonitoringNamespace},
+		Spec:       v1.StatefulSetSpec{Replicas: &replicas},
+		Status:     v1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+	if err := i.Informer().GetIndexer().Add(st); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	created, err := svc.IsCreated()
+	if err != nil {
+		t.Fatalf("unexpected error checking statefulset, error %v", err)
+	}
+	if created {
+		t.Error("statefulset with pending replicas not expected as created")
+	}
+
+	pm := &v1alpha1.PrometheusServer{}
+	if err := svc.(service2.StatusReporter).ReportStatus(pm); err != nil {
+		t.Fatalf("unexpected error reporting status, error %v", err)
+	}
+	if expected, got := replicas, pm.Status.Replicas; expected != got {
+		t.Errorf("replicas do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := int32(1), pm.Status.ReadyReplicas; expected != got {
+		t.Errorf("ready replicas do not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItCreatesStatefulSetWithCustomImageOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	img := Image{Repository: "registry.internal/prom/prometheus", PullPolicy: corev1.PullAlways, PullSecrets: []string{"registry-credentials"}}
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), img, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	digest := "sha256:2d6c2a5e9b1a0b8d1f4e1c3a7f6c5b4a3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version: "v1.0.1",
+			Image:   &v1alpha1.ImageSpec{Digest: digest, PullPolicy: corev1.PullIfNotPresent},
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	v, ok := clientSet.Actions()[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clientSet.Actions()[0])
+	}
+
+	d, ok := v.GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	spec := d.Spec.Template.Spec
+	if expected, got := img.Repository+"@"+digest, spec.Containers[0].Image; expected != got {
+		t.Errorf("image does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := corev1.PullIfNotPresent, spec.Containers[0].ImagePullPolicy; expected != got {
+		t.Errorf("pull policy does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, len(spec.ImagePullSecrets); expected != got {
+		t.Fatalf("pull secrets do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "registry-credentials", spec.ImagePullSecrets[0].Name; expected != got {
+		t.Errorf("pull secret does not match, expected %s got %s", expected, got)
+	}
+
+	if err := i.Informer().GetIndexer().Add(d); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+	if err := svc.(service2.StatusReporter).ReportStatus(pm); err != nil {
+		t.Fatalf("unexpected error reporting status, error %v", err)
+	}
+	if expected, got := img.Repository+"@"+digest, pm.Status.Image; expected != got {
+		t.Errorf("status image does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItCreatesStatefulSetWithRetentionResourcesAndSchedulingOnCreationRequest(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version:       "v1.0.1",
+			Retention:     "15d",
+			RetentionSize: "8GB",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			},
+			NodeSelector:      map[string]string{"role": "monitoring"},
+			Tolerations:       []corev1.Toleration{{Key: "monitoring", Operator: corev1.TolerationOpExists}},
+			PriorityClassName: "high",
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	v, ok := clActions[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+
+	d, ok := v.GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	spec := d.Spec.Template.Spec
+	args := spec.Containers[0].Args
+	for _, arg := range []string{"--storage.tsdb.retention.time=15d", "--storage.tsdb.retention.size=8GB"} {
+		if !contains(args, arg) {
+			t.Errorf("expected arg %s not found on %v", arg, args)
+		}
+	}
+	if expected, got := resource.MustParse("512Mi"), spec.Containers[0].Resources.Requests[corev1.ResourceMemory]; expected.Cmp(got) != 0 {
+		t.Errorf("memory request does not match, expected %s got %s", expected.String(), got.String())
+	}
+	if expected, got := "monitoring", spec.NodeSelector["role"]; expected != got {
+		t.Errorf("node selector does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, len(spec.Tolerations); expected != got {
+		t.Errorf("tolerations do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "high", spec.PriorityClassName; expected != got {
+		t.Errorf("priority class does not match, expected %s got %s", expected, got)
+	}
+	if spec.SecurityContext == nil || spec.SecurityContext.RunAsNonRoot == nil || !*spec.SecurityContext.RunAsNonRoot {
+		t.Error("expected default non root pod security context")
+	}
+	if sc := spec.Containers[0].SecurityContext; sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Error("expected default container security context without privilege escalation")
+	}
+}
+
+func TestItConfiguresExternalURLAndRoutePrefixFromIngress(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version: "v1.0.1",
+			Ingress: &v1alpha1.IngressSpec{Host: "prometheus.example.com", Path: "/prometheus"},
+		},
+	}
+
+	c := (&statefulSet{}).container(pm, "")
+	for _, arg := range []string{"--web.external-url=http://prometheus.example.com/prometheus", "--web.route-prefix=/prometheus"} {
+		if !contains(c.Args, arg) {
+			t.Errorf("expected arg %s not found on %v", arg, c.Args)
+		}
+	}
+	if expected, got := "/prometheus/-/ready", c.ReadinessProbe.HTTPGet.Path; expected != got {
+		t.Errorf("readiness path does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItCreatesStatefulSetWithConfigCheckSidecarsAndExtraVolumes(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{
+			Version:        "v1.0.1",
+			ValidateConfig: true,
+			Sidecars: []corev1.Container{
+				{Name: "thanos-sidecar", Image: "thanosio/thanos:v0.25.2", Args: []string{"sidecar"}},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "rules", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+			VolumeMounts: []corev1.VolumeMount{{Name: "rules", MountPath: "/etc/prometheus/rules"}},
+		},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure statefulset creation, error %v", err)
+	}
+
+	v, ok := clientSet.Actions()[0].(k8stest.CreateAction)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clientSet.Actions()[0])
+	}
+	d, ok := v.GetObject().(*v1.StatefulSet)
+	if !ok {
+		t.Fatalf("unexpected type got %T", v.GetObject())
+	}
+
+	spec := d.Spec.Template.Spec
+	if expected, got := 1, len(spec.InitContainers); expected != got {
+		t.Fatalf("init containers do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := promtoolPath, spec.InitContainers[0].Command[0]; expected != got {
+		t.Errorf("config check command does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 2, len(spec.Containers); expected != got {
+		t.Fatalf("containers do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "thanos-sidecar", spec.Containers[1].Name; expected != got {
+		t.Errorf("sidecar does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := "rules", spec.Volumes[len(spec.Volumes)-1].Name; expected != got {
+		t.Errorf("extra volume does not match, expected %s got %s", expected, got)
+	}
+	mounts := spec.Containers[0].VolumeMounts
+	if expected, got := "rules", mounts[len(mounts)-1].Name; expected != got {
+		t.Errorf("extra volume mount does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItDetectsStatefulSetDriftFromPrometheusServerSpec(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	pvc := sif.Core().V1().PersistentVolumeClaims()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), pvc.Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1", Retention: "15d"},
+	}
+
+	d := svc.(*statefulSet)
+	drifted, err := d.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if drifted {
+		t.Fatal("not found statefulset not expected as drifted")
+	}
+
+	st, err := d.build(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building statefulset %v", err)
+	}
+	if err := i.Informer().GetIndexer().Add(st); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+	drifted, err = d.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if drifted {
+		t.Fatal("statefulset built from spec not expected as drifted")
+	}
+
+	st.Spec.Template.Spec.Containers[0].Args = []string{prometheusConfigFileArg}
+	if err := i.Informer().GetIndexer().Update(st); err != nil {
+		t.Fatalf("unable to update entry on indexer %v", err)
+	}
+	drifted, err = d.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if !drifted {
+		t.Fatal("statefulset with modified args expected as drifted")
+	}
+}
+
+func TestItDetectsStatefulSetDriftFromPodTemplateHash(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+
+	svc := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1"},
+	}
+
+	d := svc.(*statefulSet)
+	st, err := d.build(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building statefulset %v", err)
+	}
+	if err := i.Informer().GetIndexer().Add(st); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	pm.Spec.Storage = &v1alpha1.StorageSpec{Size: resource.MustParse("10Gi")}
+	drifted, err := d.IsDrifted(pm)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift, error %v", err)
+	}
+	if !drifted {
+		t.Fatal("statefulset with pod volume changes expected as drifted")
+	}
+}
+
+func TestItRestartsStatefulSetPatchingPodTemplateAnnotation(t *testing.T) {
+	st := &v1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: prometheusStatefulSetName, Namespace: service2.MonitoringNamespace, Labels: shardLabels(0)},
+	}
+	clientSet := fake.NewSimpleClientset(st)
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	i := sif.Apps().V1().StatefulSets()
+	if err := i.Informer().GetIndexer().Add(st); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	svc := NewStatefulSet(clientSet, i.Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), sif.Core().V1().Pods().Lister(), Image{}, nil)
+	if err := svc.(service2.Restarter).Restart(context.Background(), &v1alpha1.PrometheusServer{}, "2022-05-10T10:10:10Z"); err != nil {
+		t.Fatalf("unexpected error restarting statefulset %v", err)
+	}
+
+	res, err := clientSet.AppsV1().StatefulSets(service2.MonitoringNamespace).Get(context.Background(), prometheusStatefulSetName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting statefulset %v", err)
+	}
+	if expected, got := "2022-05-10T10:10:10Z", res.Spec.Template.Annotations[restartedAtAnnotation]; expected != got {
+		t.Errorf("restarted at annotation does not match, expected %s got %s", expected, got)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestItDetectsConfigLoadFailureFromPodTerminationMessage(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	pods := sif.Core().V1().Pods()
+	svc := NewStatefulSet(clientSet, sif.Apps().V1().StatefulSets().Lister(), sif.Core().V1().PersistentVolumeClaims().Lister(), pods.Lister(), Image{}, nil).(service2.FailureDetector)
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Version: "v2.35.0"}}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-server-0", Namespace: service2.MonitoringNamespace, Labels: colorLabels(0, v1alpha1.BlueColor)},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: service2.MonitoringName,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message:  "level=info msg=\"Starting Prometheus\"\nlevel=error msg=\"Error loading config (--config.file=/etc/prometheus/prometheus.yml)\" err=\"yaml: line 2\"\n",
+				}},
+			}},
+		},
+	}
+	if err := pods.Informer().GetIndexer().Add(pod); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+
+	reason, err := svc.Failure(pm)
+	if err != nil {
+		t.Fatalf("unexpected error detecting failure %v", err)
+	}
+	expected := `pod prometheus-server-0 config load failed: level=error msg="Error loading config (--config.file=/etc/prometheus/prometheus.yml)" err="yaml: line 2"`
+	if reason != expected {
+		t.Errorf("failure reason does not match, expected %s got %s", expected, reason)
+	}
+
+	pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.Message = "level=error msg=\"OOM\""
+	if err := pods.Informer().GetIndexer().Update(pod); err != nil {
+		t.Fatalf("unable to update entry on indexer %v", err)
+	}
+	reason, err = svc.Failure(pm)
+	if err != nil {
+		t.Fatalf("unexpected error detecting failure %v", err)
+	}
+	if reason != "" {
+		t.Errorf("unexpected failure %s", reason)
+	}
+}
@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+)
+
+const configTemplateName = "prometheus.yml"
+const secretTemplateFunc = "secret"
+
+// TemplateVars defines operator level variables available on PrometheusServer config templates
+type TemplateVars map[string]string
+
+// templateData is the config template root object
+type templateData struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Vars        TemplateVars
+}
+
+// renderConfig executes PrometheusServer config as template when it's enabled, raw config is returned otherwise
+func renderConfig(obj *v1alpha1.PrometheusServer, vars TemplateVars) (string, error) {
+	if !obj.Spec.TemplateConfig {
+		return obj.Spec.Config, nil
+	}
+
+	t, err := parseConfigTemplate(obj.Spec.Config)
+	if err != nil {
+		return "", err
+	}
+
+	data := templateData{
+		Name:        obj.Name,
+		Namespace:   obj.Namespace,
+		Labels:      obj.Labels,
+		Annotations: obj.Annotations,
+		Vars:        vars,
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("unable to render prometheus config template, error %w", err)
+	}
+	return b.String(), nil
+}
+
+// parseConfigTemplate parses config template failing on missing keys
+func parseConfigTemplate(raw string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		secretTemplateFunc: func(name, key string) string {
+			return secretFile(v1alpha1.SecretKeyRef{Name: name, Key: key})
+		},
+	}
+	t, err := template.New(configTemplateName).Option("missingkey=error").Funcs(funcs).Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse prometheus config template, error %w", err)
+	}
+	return t, nil
+}
+
+// templateSecrets returns Secret names referenced by config template placeholders, in order of appearance
+func templateSecrets(obj *v1alpha1.PrometheusServer) []string {
+	if !obj.Spec.TemplateConfig {
+		return nil
+	}
+
+	t, err := parseConfigTemplate(obj.Spec.Config)
+	if err != nil {
+		return nil
+	}
+
+	var res []string
+	walkCommands(t.Tree.Root, func(cmd *parse.CommandNode) {
+		if len(cmd.Args) < 2 {
+			return
+		}
+		if id, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || id.Ident != secretTemplateFunc {
+			return
+		}
+		if name, ok := cmd.Args[1].(*parse.StringNode); ok {
+			res = append(res, name.Text)
+		}
+	})
+	return res
+}
+
+// walkCommands visits each template command, pipelines inside branches and arguments included
+func walkCommands(node parse.Node, fn func(*parse.CommandNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkCommands(c, fn)
+		}
+	case *parse.ActionNode:
+		walkCommands(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkCommands(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkCommands(c, fn)
+		}
+	case *parse.CommandNode:
+		fn(n)
+		for _, arg := range n.Args {
+			walkCommands(arg, fn)
+		}
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(*parse.CommandNode)) {
+	walkCommands(n.Pipe, fn)
+	walkCommands(n.List, fn)
+	walkCommands(n.ElseList, fn)
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestItRendersConfigTemplateWithMetadataVarsAndSecretPlaceholders(t *testing.T) {
+	raw := `scrape_configs:
+- job_name: {{ .Namespace }}-{{ .Name }}
+  basic_auth:
+    username: {{ index .Labels "team" }}
+    password_file: {{ secret "scrape-auth" "password" }}
+  params:
+    cluster: [{{ .Vars.cluster }}]
+`
+	pm := &v1alpha1.PrometheusServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus", Labels: map[string]string{"team": "platform"}},
+		Spec:       v1alpha1.PrometheusServerSpec{Config: raw, TemplateConfig: true},
+	}
+
+	res, err := renderConfig(pm, TemplateVars{"cluster": "production"})
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: default-prometheus
+  basic_auth:
+    username: platform
+    password_file: /etc/prometheus-secrets/scrape-auth/password
+  params:
+    cluster: [production]
+`
+	if expected != res {
+		t.Errorf("config does not match, expected %s got %s", expected, res)
+	}
+}
+
+func TestItKeepsRawConfigWhenTemplateIsNotEnabled(t *testing.T) {
+	raw := "scrape_configs: []\n# {{ .Name }}\n"
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Config: raw}}
+
+	res, err := renderConfig(pm, nil)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+	if raw != res {
+		t.Errorf("config does not match, expected %s got %s", raw, res)
+	}
+}
+
+func TestItFailsRenderingConfigTemplateWithMissingVar(t *testing.T) {
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Config: "global:\n  scrape_interval: {{ .Vars.interval }}\n", TemplateConfig: true},
+	}
+
+	if _, err := renderConfig(pm, TemplateVars{}); err == nil {
+		t.Fatal("expected missing var error")
+	}
+}
+
+func TestItFindsSecretsReferencedByConfigTemplate(t *testing.T) {
+	raw := `{{ if .Vars.auth }}password_file: {{ secret "foo" "password" }}{{ else }}{{ secret "bar" "token" | printf "%s" }}{{ end }}`
+	pm := &v1alpha1.PrometheusServer{Spec: v1alpha1.PrometheusServerSpec{Config: raw, TemplateConfig: true}}
+
+	res := templateSecrets(pm)
+	if expected, got := 2, len(res); expected != got {
+		t.Fatalf("secrets do not match, expected %d got %d %v", expected, got, res)
+	}
+	if res[0] != "foo" || res[1] != "bar" {
+		t.Errorf("unexpected secrets %v", res)
+	}
+
+	mounts := secretVolumeMounts(pm)
+	if expected, got := 2, len(mounts); expected != got {
+		t.Fatalf("secret mounts do not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItReportsConfigRenderErrorAsCondition(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	sif := informers.NewSharedInformerFactory(clientSet, 0)
+	svc := NewConfigMap(clientSet, sif.Core().V1().ConfigMaps().Lister(), nil, ExternalLabels{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := &v1alpha1.PrometheusServer{
+		Spec: v1alpha1.PrometheusServerSpec{Version: "v1.0.1", Config: "global: {{ .Foo", TemplateConfig: true},
+	}
+	if err := svc.EnsureCreation(ctx, pm); err == nil {
+		t.Fatal("expected render error")
+	}
+
+	if expected, got := 1, len(pm.Status.Conditions); expected != got {
+		t.Fatalf("conditions do not match, expected %d got %d", expected, got)
+	}
+	c := pm.Status.Conditions[0]
+	if c.Type != v1alpha1.ConfigRendered || c.Status != metav1.ConditionFalse || c.Reason != "RenderError" {
+		t.Errorf("unexpected condition %v", c)
+	}
+}
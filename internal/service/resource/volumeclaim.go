@@ -0,0 +1,180 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const storageLabel = "k8slab.info/storage"
+
+// volumeClaim takes care on Prometheus storage persistent volume claims lifecycle
+type volumeClaim struct {
+	client    kubernetes.Interface
+	lister    listersV1.PersistentVolumeClaimLister
+	namespace string
+	selector  labels.Set
+}
+
+func newVolumeClaim(cl kubernetes.Interface, l listersV1.PersistentVolumeClaimLister) *volumeClaim {
+	return &volumeClaim{
+		client:    cl,
+		lister:    l,
+		namespace: service2.MonitoringNamespace,
+		selector:  labels.Set{"app": service2.MonitoringName, storageLabel: prometheusStorageVolumeName},
+	}
+}
+
+// template builds storage claim template from PrometheusServer storage spec
+func (c *volumeClaim) template(obj *v1alpha1.PrometheusServer) corev1.PersistentVolumeClaim {
+	s := obj.Spec.Storage
+	accessMode := s.AccessMode
+	if accessMode == "" {
+		accessMode = corev1.ReadWriteOnce
+	}
+
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   prometheusStorageVolumeName,
+			Labels: c.selector,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{accessMode},
+			StorageClassName: s.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: s.Size},
+			},
+		},
+	}
+}
+
+// update expands existing claims on size growth, changes that can not be applied are reported
+func (c *volumeClaim) update(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.Spec.Storage == nil || isAgent(obj) {
+		meta.RemoveStatusCondition(&obj.Status.Conditions, v1alpha1.StorageSynced)
+		return nil
+	}
+
+	claims, err := c.lister.PersistentVolumeClaims(c.namespace).List(c.selector.AsSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claims, error %w", err)
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Name < claims[j].Name })
+
+	desired := c.template(obj)
+	var mismatches []string
+	for _, claim := range claims {
+		reason, err := c.apply(ctx, &desired, claim)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			mismatches = append(mismatches, fmt.Sprintf("claim %s %s", claim.Name, reason))
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               v1alpha1.StorageSynced,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.Generation,
+		Reason:             "StorageSynced",
+		Message:            "storage claims match storage spec",
+	}
+	if len(mismatches) > 0 {
+		cond.Status, cond.Reason = metav1.ConditionFalse, "StorageMismatch"
+		cond.Message = strings.Join(mismatches, ", ") + ", claims must be removed to apply them"
+	}
+	meta.SetStatusCondition(&obj.Status.Conditions, cond)
+	return nil
+}
+
+// apply expands claim when required, returning the reason why desired claim can not be applied
+func (c *volumeClaim) apply(ctx context.Context, desired, claim *corev1.PersistentVolumeClaim) (string, error) {
+	if sc := desired.Spec.StorageClassName; sc != nil && (claim.Spec.StorageClassName == nil || *sc != *claim.Spec.StorageClassName) {
+		return fmt.Sprintf("storage class can not change to %s", *sc), nil
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec.AccessModes, claim.Spec.AccessModes) {
+		return fmt.Sprintf("access modes can not change to %v", desired.Spec.AccessModes), nil
+	}
+
+	size, current := desired.Spec.Resources.Requests[corev1.ResourceStorage], claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch size.Cmp(current) {
+	case 0:
+		return "", nil
+	case -1:
+		return fmt.Sprintf("size can not shrink from %s to %s", current.String(), size.String()), nil
+	}
+
+	expandable, err := c.expandable(ctx, claim)
+	if err != nil {
+		return "", err
+	}
+	if !expandable {
+		return fmt.Sprintf("storage class does not allow volume expansion to %s", size.String()), nil
+	}
+
+	log.Debugf("expanding persistent volume claim %s to %s", claim.Name, size.String())
+	cp := claim.DeepCopy()
+	cp.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	if _, err := c.client.CoreV1().PersistentVolumeClaims(c.namespace).Update(ctx, cp, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("unable to expand persistent volume claim %s, error %w", claim.Name, err)
+	}
+	return "", nil
+}
+
+// expandable checks claim storage class allows volume expansion
+func (c *volumeClaim) expandable(ctx context.Context, claim *corev1.PersistentVolumeClaim) (bool, error) {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return false, nil
+	}
+
+	sc, err := c.client.StorageV1().StorageClasses().Get(ctx, *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to get storage class %s, error %w", *claim.Spec.StorageClassName, err)
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// ensureDeletion removes storage claims only on PrometheusServer termination with Delete reclaim policy
+func (c *volumeClaim) ensureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.DeletionTimestamp.IsZero() || reclaimPolicy(obj) != v1alpha1.StorageDelete {
+		return nil
+	}
+
+	claims, err := c.lister.PersistentVolumeClaims(c.namespace).List(c.selector.AsSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claims, error %w", err)
+	}
+
+	for _, claim := range claims {
+		log.Debugf("removing persistent volume claim %s", claim.Name)
+		err := c.client.CoreV1().PersistentVolumeClaims(c.namespace).Delete(ctx, claim.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete persistent volume claim %s, error %w", claim.Name, err)
+		}
+	}
+	return nil
+}
+
+func reclaimPolicy(obj *v1alpha1.PrometheusServer) string {
+	if obj.Spec.Storage == nil || obj.Spec.Storage.ReclaimPolicy == "" {
+		return v1alpha1.StorageRetain
+	}
+	return obj.Spec.Storage.ReclaimPolicy
+}
@@ -0,0 +1,420 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	svc "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/promconfig"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+)
+
+const prometheusWebConfigName = svc.MonitoringName + "-web-config"
+const prometheusWebCredentialsName = svc.MonitoringName + "-web-credentials"
+const prometheusSelfScrapeName = svc.MonitoringName + "-self-scrape"
+const webConfigResourceName = "web-config"
+const webConfigKey = "web-config.yml"
+const webPasswordKey = "password"
+const webConfigPath = "/etc/prometheus-web/"
+const webTLSPath = "/etc/prometheus-web-tls/"
+const selfScrapePath = "/etc/prometheus-self-scrape/"
+const webConfigVolumeName = "prometheus-web-config-volume"
+const webTLSVolumeName = "prometheus-web-tls-volume"
+const selfScrapeVolumeName = "prometheus-self-scrape-volume"
+const basicAuthUsersKey = "basic_auth_users"
+
+// WebUser is the basic auth user reserved to the operator, its password is only readable by the operator
+const WebUser = "prometheus-operator"
+
+// selfScrapeUser is the basic auth user reserved to Prometheus self scrape, its password is mounted on Prometheus pods
+const selfScrapeUser = svc.MonitoringName
+
+type webConfig struct {
+	client    kubernetes.Interface
+	lister    listersV1.SecretLister
+	namespace string
+	name      string
+	verified  map[string]string // password hashes already checked against their passwords
+	mutex     sync.Mutex
+}
+
+// NewWebConfig instantiates Prometheus web config resource enforcer
+func NewWebConfig(cl kubernetes.Interface, l listersV1.SecretLister) svc.ResourceEnforcer {
+	return &webConfig{
+		client:    cl,
+		lister:    l,
+		namespace: svc.MonitoringNamespace,
+		name:      prometheusWebConfigName,
+		verified:  map[string]string{},
+	}
+}
+
+// EnsureCreation creates active color web config secret or updates it in place, user passwords are kept
+func (c *webConfig) EnsureCreation(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	return c.apply(ctx, obj)
+}
+
+// EnsureDeletion removes web config and credential secrets on termination or once http endpoint is not secured
+func (c *webConfig) EnsureDeletion(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	if obj.DeletionTimestamp.IsZero() && webSecured(obj) {
+		return nil
+	}
+
+	names := []string{colorName(c.name, v1alpha1.BlueColor), colorName(c.name, v1alpha1.GreenColor), prometheusWebCredentialsName, prometheusSelfScrapeName}
+	for _, name := range names {
+		log.Debugf("removing web config secret %s", name)
+		err := c.client.CoreV1().Secrets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete web config secret %s, error %w", name, err)
+		}
+	}
+	return nil
+}
+
+// IsCreated check if web config secret exists on any color
+func (c *webConfig) IsCreated() (bool, error) {
+	for _, color := range []string{v1alpha1.BlueColor, v1alpha1.GreenColor} {
+		_, err := c.lister.Secrets(c.namespace).Get(colorName(c.name, color))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("unable to get web config secret %w", err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// IsRequired checks if PrometheusServer secures its http endpoint
+func (c *webConfig) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return webSecured(obj)
+}
+
+// UpdatesInPlace returns true, web config secret is updated in place
+func (c *webConfig) UpdatesInPlace() bool {
+	return true
+}
+
+// UpdateConfig renders web config again from referenced Secrets and updates it in place
+func (c *webConfig) UpdateConfig(ctx context.Context, obj *v1alpha1.PrometheusServer) (bool, error) {
+	if !webSecured(obj) {
+		return false, nil
+	}
+	return false, c.apply(ctx, obj)
+}
+
+// EnsureColorCreation creates web config secret on color, candidate pods mount their own web config
+func (c *webConfig) EnsureColorCreation(ctx context.Context, obj *v1alpha1.PrometheusServer, color string) error {
+	if !webSecured(obj) {
+		return nil
+	}
+	return c.apply(ctx, withColor(obj, color))
+}
+
+// IsColorReady returns true, web config secret is ready once created
+func (c *webConfig) IsColorReady(_ context.Context, _ *v1alpha1.PrometheusServer, _ string) (bool, error) {
+	return true, nil
+}
+
+// EnsureColorDeletion removes web config secret on color, user passwords are kept
+func (c *webConfig) EnsureColorDeletion(ctx context.Context, _ *v1alpha1.PrometheusServer, color string) error {
+	name := colorName(c.name, color)
+	log.Debugf("removing web config secret %s", name)
+	err := c.client.CoreV1().Secrets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete web config secret %s, error %w", name, err)
+	}
+	return nil
+}
+
+// Name returns resource enforcer target name
+func (c *webConfig) Name() string {
+	return webConfigResourceName
+}
+
+// apply renders web config and creates or updates its active color secret when it differs
+func (c *webConfig) apply(ctx context.Context, obj *v1alpha1.PrometheusServer) error {
+	name := colorName(c.name, activeColor(obj))
+	live, err := c.lister.Secrets(c.namespace).Get(name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get web config secret %w", err)
+	}
+
+	var current struct {
+		Users map[string]string `yaml:"basic_auth_users"`
+	}
+	if live != nil {
+		// unparseable web config is replaced with a fresh one
+		_ = yaml.Unmarshal(live.Data[webConfigKey], &current)
+	}
+
+	hashes := map[string]string{}
+	if webBasicAuth(obj) {
+		for _, u := range []struct{ user, secret string }{
+			{WebUser, prometheusWebCredentialsName},
+			{selfScrapeUser, prometheusSelfScrapeName},
+		} {
+			password, err := c.password(ctx, u.secret)
+			if err != nil {
+				return err
+			}
+			hash, err := c.passwordHash(password, current.Users[u.user])
+			if err != nil {
+				return err
+			}
+			hashes[u.user] = hash
+		}
+	}
+
+	cfg, err := c.render(obj, hashes)
+	if err != nil {
+		return err
+	}
+
+	if live == nil {
+		log.Debugf("creating web config secret %s", name)
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: c.namespace,
+			},
+			Data: map[string][]byte{webConfigKey: []byte(cfg)},
+		}
+		if _, err := c.client.CoreV1().Secrets(c.namespace).Create(ctx, s, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to create web config secret, error %w", err)
+		}
+		return nil
+	}
+
+	if cfg == string(live.Data[webConfigKey]) {
+		return nil
+	}
+
+	log.Debugf("updating web config secret %s", name)
+	s := live.DeepCopy()
+	s.Data = map[string][]byte{webConfigKey: []byte(cfg)}
+	if _, err := c.client.CoreV1().Secrets(c.namespace).Update(ctx, s, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update web config secret, error %w", err)
+	}
+	return nil
+}
+
+// password returns user password from its Secret, a random one is generated when it does not exist
+func (c *webConfig) password(ctx context.Context, name string) (string, error) {
+	s, err := c.lister.Secrets(c.namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		s, err = c.client.CoreV1().Secrets(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err == nil {
+		return string(s.Data[webPasswordKey]), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("unable to get web credentials secret %s, error %w", name, err)
+	}
+
+	log.Debugf("creating web credentials secret %s", name)
+	password, err := randomPassword()
+	if err != nil {
+		return "", err
+	}
+	s = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+		},
+		Data: map[string][]byte{webPasswordKey: []byte(password)},
+	}
+	if _, err := c.client.CoreV1().Secrets(c.namespace).Create(ctx, s, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("unable to create web credentials secret %s, error %w", name, err)
+	}
+	return password, nil
+}
+
+// render builds Prometheus web config, basic auth users are sorted by name with reserved users added to them
+func (c *webConfig) render(obj *v1alpha1.PrometheusServer, reserved map[string]string) (string, error) {
+	web := obj.Spec.Web
+	res := yaml.MapSlice{}
+	if web.TLSSecretRef != nil {
+		res = append(res, yaml.MapItem{Key: "tls_server_config", Value: yaml.MapSlice{
+			{Key: "cert_file", Value: path.Join(webTLSPath, corev1.TLSCertKey)},
+			{Key: "key_file", Value: path.Join(webTLSPath, corev1.TLSPrivateKeyKey)},
+		}})
+	}
+
+	if web.BasicAuthUsers != nil {
+		s, err := c.lister.Secrets(c.namespace).Get(web.BasicAuthUsers.Name)
+		if err != nil {
+			return "", fmt.Errorf("unable to get basic auth users secret %s, error %w", web.BasicAuthUsers.Name, err)
+		}
+
+		var users []string
+		for user := range reserved {
+			users = append(users, user)
+		}
+		for user := range s.Data {
+			if _, ok := reserved[user]; ok {
+				return "", fmt.Errorf("basic auth user %s is reserved", user)
+			}
+			users = append(users, user)
+		}
+		sort.Strings(users)
+
+		hashes := yaml.MapSlice{}
+		for _, user := range users {
+			hash, ok := reserved[user]
+			if !ok {
+				hash = string(s.Data[user])
+			}
+			hashes = append(hashes, yaml.MapItem{Key: user, Value: hash})
+		}
+		res = append(res, yaml.MapItem{Key: basicAuthUsersKey, Value: hashes})
+	}
+
+	out, err := yaml.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal web config, error %w", err)
+	}
+	return string(out), nil
+}
+
+// webSecured checks if PrometheusServer serves https or requires basic auth
+func webSecured(obj *v1alpha1.PrometheusServer) bool {
+	w := obj.Spec.Web
+	return w != nil && (w.TLSSecretRef != nil || w.BasicAuthUsers != nil)
+}
+
+func webTLS(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Web != nil && obj.Spec.Web.TLSSecretRef != nil
+}
+
+func webBasicAuth(obj *v1alpha1.PrometheusServer) bool {
+	return obj.Spec.Web != nil && obj.Spec.Web.BasicAuthUsers != nil
+}
+
+// appendSelfScrapeJob scrapes basic auth protected Prometheus with the self scrape user
+func appendSelfScrapeJob(cfg *promconfig.Config, obj *v1alpha1.PrometheusServer) {
+	if !webBasicAuth(obj) {
+		return
+	}
+	for _, name := range cfg.JobNames() {
+		if name == svc.MonitoringName {
+			return
+		}
+	}
+
+	job := yaml.MapSlice{
+		{Key: "job_name", Value: svc.MonitoringName},
+		{Key: "metrics_path", Value: path.Join(routePrefix(obj), "/metrics")},
+		{Key: "scheme", Value: strings.ToLower(string(webScheme(obj)))},
+		{Key: "basic_auth", Value: yaml.MapSlice{
+			{Key: "username", Value: selfScrapeUser},
+			{Key: "password_file", Value: path.Join(selfScrapePath, webPasswordKey)},
+		}},
+	}
+	if webTLS(obj) {
+		// certificate is verified against itself for headless Service name, as it's not issued for localhost
+		job = append(job, yaml.MapItem{Key: "tls_config", Value: yaml.MapSlice{
+			{Key: "ca_file", Value: path.Join(webTLSPath, corev1.TLSCertKey)},
+			{Key: "server_name", Value: headlessServiceHost()},
+		}})
+	}
+	job = append(job, yaml.MapItem{Key: "static_configs", Value: []yaml.MapSlice{
+		{{Key: "targets", Value: []string{fmt.Sprintf("localhost:%d", prometheusHttpPort)}}},
+	}})
+	cfg.Append(scrapeConfigsKey, job)
+}
+
+// webScheme returns Prometheus http endpoint scheme
+func webScheme(obj *v1alpha1.PrometheusServer) corev1.URIScheme {
+	if webTLS(obj) {
+		return corev1.URISchemeHTTPS
+	}
+	return corev1.URISchemeHTTP
+}
+
+// webVolumes mounts active color web config, TLS and self scrape credentials Secrets
+func webVolumes(obj *v1alpha1.PrometheusServer) []corev1.Volume {
+	if !webSecured(obj) {
+		return nil
+	}
+
+	res := []corev1.Volume{{
+		Name:         webConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: colorName(prometheusWebConfigName, activeColor(obj))}},
+	}}
+	if webTLS(obj) {
+		res = append(res, corev1.Volume{
+			Name:         webTLSVolumeName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: obj.Spec.Web.TLSSecretRef.Name}},
+		})
+	}
+	if webBasicAuth(obj) {
+		res = append(res, corev1.Volume{
+			Name:         selfScrapeVolumeName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: prometheusSelfScrapeName}},
+		})
+	}
+	return res
+}
+
+func webVolumeMounts(obj *v1alpha1.PrometheusServer) []corev1.VolumeMount {
+	if !webSecured(obj) {
+		return nil
+	}
+
+	res := []corev1.VolumeMount{{Name: webConfigVolumeName, MountPath: webConfigPath, ReadOnly: true}}
+	if webTLS(obj) {
+		res = append(res, corev1.VolumeMount{Name: webTLSVolumeName, MountPath: webTLSPath, ReadOnly: true})
+	}
+	if webBasicAuth(obj) {
+		res = append(res, corev1.VolumeMount{Name: selfScrapeVolumeName, MountPath: selfScrapePath, ReadOnly: true})
+	}
+	return res
+}
+
+// passwordHash keeps current hash while it matches password, a new one is generated otherwise
+func (c *webConfig) passwordHash(password, current string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if p, ok := c.verified[current]; ok && p == password {
+		return current, nil
+	}
+	if current != "" && bcrypt.CompareHashAndPassword([]byte(current), []byte(password)) == nil {
+		c.verified[current] = password
+		return current, nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash password, error %w", err)
+	}
+	c.verified[string(hash)] = password
+	return string(hash), nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate password, error %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
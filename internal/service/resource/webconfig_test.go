@@ -0,0 +1,326 @@
+package resource
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	service2 "github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	listersV1 "k8s.io/client-go/listers/core/v1"
+	k8stest "k8s.io/client-go/testing"
+)
+
+func TestItCreatesWebConfigSecretWithReservedUsers(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	indexer := newIndexer(t, webUsers(map[string][]byte{"bob": []byte("$2y$10$bob"), "alice": []byte("$2y$10$alice")}))
+	wc := NewWebConfig(clientSet, listersV1.NewSecretLister(indexer)).(*webConfig)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+	if err := wc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure web config creation, error %v", err)
+	}
+
+	created := createdSecrets(clientSet)
+	if expected, got := 3, len(created); expected != got {
+		t.Fatalf("unexpected total secrets created, expected %d got %d", expected, got)
+	}
+	s := created[prometheusWebConfigName]
+	if expected, got := 1, len(s.Data); expected != got {
+		t.Fatalf("web config secret keys do not match, expected %d got %d", expected, got)
+	}
+
+	var cfg struct {
+		TLS   map[string]string `yaml:"tls_server_config"`
+		Users yaml.MapSlice     `yaml:"basic_auth_users"`
+	}
+	if err := yaml.Unmarshal(s.Data[webConfigKey], &cfg); err != nil {
+		t.Fatalf("unable to unmarshal web config %v", err)
+	}
+	if expected, got := "/etc/prometheus-web-tls/tls.crt", cfg.TLS["cert_file"]; expected != got {
+		t.Errorf("cert file does not match, expected %s got %s", expected, got)
+	}
+
+	if expected, got := 4, len(cfg.Users); expected != got {
+		t.Fatalf("users do not match, expected %d got %d", expected, got)
+	}
+	if cfg.Users[0].Key != "alice" || cfg.Users[1].Key != "bob" || cfg.Users[2].Key != WebUser || cfg.Users[3].Key != selfScrapeUser {
+		t.Errorf("unexpected users order %v", cfg.Users)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(cfg.Users[2].Value.(string)), created[prometheusWebCredentialsName].Data[webPasswordKey]); err != nil {
+		t.Errorf("operator password does not match its hash %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(cfg.Users[3].Value.(string)), created[prometheusSelfScrapeName].Data[webPasswordKey]); err != nil {
+		t.Errorf("self scrape password does not match its hash %v", err)
+	}
+}
+
+func TestItRejectsReservedBasicAuthUser(t *testing.T) {
+	indexer := newIndexer(t, webUsers(map[string][]byte{WebUser: []byte("$2y$10$foo")}))
+	wc := NewWebConfig(fake.NewSimpleClientset(), listersV1.NewSecretLister(indexer)).(*webConfig)
+
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+	if _, err := wc.render(pm, map[string]string{WebUser: "hash", selfScrapeUser: "hash"}); err == nil {
+		t.Fatal("expected reserved user error")
+	}
+}
+
+func TestItUpdatesWebConfigInPlaceKeepingPasswords(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	indexer := newIndexer(t, webUsers(map[string][]byte{"alice": []byte("$2y$10$alice")}))
+	wc := NewWebConfig(clientSet, listersV1.NewSecretLister(indexer)).(*webConfig)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := wc.EnsureCreation(ctx, pm); err != nil {
+		t.Fatalf("unable to ensure web config creation, error %v", err)
+	}
+	created := createdSecrets(clientSet)
+	for _, s := range created {
+		if err := indexer.Add(s); err != nil {
+			t.Fatalf("unable to add entry to indexer %v", err)
+		}
+	}
+	clientSet.ClearActions()
+
+	if _, err := wc.UpdateConfig(ctx, pm); err != nil {
+		t.Fatalf("unexpected error updating web config %v", err)
+	}
+	if expected, got := 0, len(clientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed on unchanged users, expected %d got %d", expected, got)
+	}
+
+	if err := indexer.Update(webUsers(map[string][]byte{"alice": []byte("$2y$10$other")})); err != nil {
+		t.Fatalf("unable to update entry on indexer %v", err)
+	}
+	if _, err := wc.UpdateConfig(ctx, pm); err != nil {
+		t.Fatalf("unexpected error updating web config %v", err)
+	}
+
+	clActions := clientSet.Actions()
+	if expected, got := 1, len(clActions); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+	s, ok := clActions[0].(k8stest.UpdateAction).GetObject().(*corev1.Secret)
+	if !ok {
+		t.Fatalf("unexpected type got %T", clActions[0])
+	}
+	if !strings.Contains(string(s.Data[webConfigKey]), "$2y$10$other") {
+		t.Errorf("expected updated users, got %s", s.Data[webConfigKey])
+	}
+
+	var before, after struct {
+		Users map[string]string `yaml:"basic_auth_users"`
+	}
+	if err := yaml.Unmarshal(created[prometheusWebConfigName].Data[webConfigKey], &before); err != nil {
+		t.Fatalf("unable to unmarshal web config %v", err)
+	}
+	if err := yaml.Unmarshal(s.Data[webConfigKey], &after); err != nil {
+		t.Fatalf("unable to unmarshal web config %v", err)
+	}
+	if before.Users[WebUser] != after.Users[WebUser] {
+		t.Error("expected operator password hash kept")
+	}
+}
+
+func TestItKeepsWebConfigSecretsOnReload(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	wc := NewWebConfig(clientSet, listersV1.NewSecretLister(newIndexer(t))).(*webConfig)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+
+	if err := wc.EnsureDeletion(context.Background(), pm); err != nil {
+		t.Fatalf("unexpected error ensuring deletion %v", err)
+	}
+	if expected, got := 0, len(clientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+
+	now := metav1.Now()
+	pm.DeletionTimestamp = &now
+	if err := wc.EnsureDeletion(context.Background(), pm); err != nil {
+		t.Fatalf("unexpected error ensuring deletion %v", err)
+	}
+	if expected, got := 4, len(clientSet.Actions()); expected != got {
+		t.Fatalf("unexpected total actions executed, expected %d got %d", expected, got)
+	}
+}
+
+func TestItSkipsPasswordHashCheckOnVerifiedHashes(t *testing.T) {
+	wc := NewWebConfig(fake.NewSimpleClientset(), listersV1.NewSecretLister(newIndexer(t))).(*webConfig)
+	hash, err := wc.passwordHash("secret", "")
+	if err != nil {
+		t.Fatalf("unexpected error hashing password %v", err)
+	}
+	if got, err := wc.passwordHash("secret", hash); err != nil || got != hash {
+		t.Errorf("expected hash kept, got %s error %v", got, err)
+	}
+
+	wc.verified["$2y$10$unchecked"] = "secret"
+	if got, err := wc.passwordHash("secret", "$2y$10$unchecked"); err != nil || got != "$2y$10$unchecked" {
+		t.Errorf("expected verified hash kept without check, got %s error %v", got, err)
+	}
+	if got, _ := wc.passwordHash("other", "$2y$10$unchecked"); got == "$2y$10$unchecked" {
+		t.Error("expected new hash on password change")
+	}
+}
+
+func TestItRollsOutWebConfigSecretPerColor(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	indexer := newIndexer(t, webUsers(map[string][]byte{"alice": []byte("$2y$10$alice")}))
+	wc := NewWebConfig(clientSet, listersV1.NewSecretLister(indexer)).(*webConfig)
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+
+	if err := wc.EnsureColorCreation(context.Background(), pm, v1alpha1.GreenColor); err != nil {
+		t.Fatalf("unexpected error creating color %v", err)
+	}
+	if err := wc.EnsureColorDeletion(context.Background(), pm, v1alpha1.GreenColor); err != nil {
+		t.Fatalf("unexpected error deleting color %v", err)
+	}
+
+	var names []string
+	for _, action := range clientSet.Actions() {
+		switch a := action.(type) {
+		case k8stest.CreateAction:
+			if s, ok := a.GetObject().(*corev1.Secret); ok {
+				names = append(names, a.GetVerb()+" "+s.Name)
+			}
+		case k8stest.DeleteAction:
+			names = append(names, a.GetVerb()+" "+a.GetName())
+		}
+	}
+	expected := "delete " + colorName(prometheusWebConfigName, v1alpha1.GreenColor)
+	if got := names[len(names)-1]; expected != got {
+		t.Errorf("expected last action %s, got %s", expected, got)
+	}
+	found := false
+	for _, n := range names {
+		if n == "create "+colorName(prometheusWebConfigName, v1alpha1.GreenColor) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected green web config secret created, got %v", names)
+	}
+}
+
+func TestItConfiguresStatefulSetAgainstSecuredEndpoint(t *testing.T) {
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+	indexer := newIndexer(t)
+	sts := &statefulSet{clients: NewPodClients(listersV1.NewSecretLister(indexer))}
+	if _, err := sts.build(pm, 0); err == nil {
+		t.Fatal("expected error building statefulset without self scrape credentials")
+	}
+
+	self := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: prometheusSelfScrapeName, Namespace: service2.MonitoringNamespace},
+		Data:       map[string][]byte{webPasswordKey: []byte("secret")},
+	}
+	if err := indexer.Add(self); err != nil {
+		t.Fatalf("unable to add entry to indexer %v", err)
+	}
+	st, err := sts.build(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error building statefulset %v", err)
+	}
+	c := st.Spec.Template.Spec.Containers[0]
+
+	if !contains(c.Args, "--web.config.file=/etc/prometheus-web/web-config.yml") {
+		t.Errorf("web config file arg not found on %v", c.Args)
+	}
+	authorization := "Basic " + base64.StdEncoding.EncodeToString([]byte(selfScrapeUser+":secret"))
+	for _, p := range []*corev1.Probe{c.LivenessProbe, c.ReadinessProbe} {
+		if p.HTTPGet == nil || p.HTTPGet.Scheme != corev1.URISchemeHTTPS {
+			t.Fatalf("expected https probes, got %v", p)
+		}
+		if len(p.HTTPGet.HTTPHeaders) != 1 || p.HTTPGet.HTTPHeaders[0].Name != "Authorization" || p.HTTPGet.HTTPHeaders[0].Value != authorization {
+			t.Errorf("expected self scrape authorization header, got %v", p.HTTPGet.HTTPHeaders)
+		}
+	}
+	if expected, got := 3, len(webVolumeMounts(pm)); expected != got {
+		t.Errorf("web mounts do not match, expected %d got %d", expected, got)
+	}
+
+	pm.Spec.Web.BasicAuthUsers = nil
+	c = (&statefulSet{}).container(pm, "")
+	if c.ReadinessProbe.HTTPGet == nil || len(c.ReadinessProbe.HTTPGet.HTTPHeaders) != 0 {
+		t.Errorf("expected https probes without authorization, got %v", c.ReadinessProbe)
+	}
+}
+
+func TestItAdjustsServiceScrapeToSecuredEndpoint(t *testing.T) {
+	pm := getFakePrometheusServer("default", "prometheus")
+	pm.Spec.Web = securedWebSpec()
+	s := (&service{}).build(pm)
+	if s.Annotations["prometheus.io/scheme"] != "https" || s.Annotations["prometheus.io/scrape"] != "false" {
+		t.Errorf("unexpected annotations %v", s.Annotations)
+	}
+
+	pm.Spec.Config = "scrape_configs: []\n"
+	cf, err := (&configMap{}).prometheusConfig(pm, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rendering config %v", err)
+	}
+
+	expected := `scrape_configs:
+- job_name: prometheus-server
+  metrics_path: /metrics
+  scheme: https
+  basic_auth:
+    username: prometheus-server
+    password_file: /etc/prometheus-self-scrape/password
+  tls_config:
+    ca_file: /etc/prometheus-web-tls/tls.crt
+    server_name: prometheus-server-headless.monitoring.svc
+  static_configs:
+  - targets:
+    - localhost:9090
+`
+	if !strings.HasPrefix(cf, expected) {
+		t.Errorf("config does not match, expected %s got %s", expected, cf)
+	}
+}
+
+func securedWebSpec() *v1alpha1.WebSpec {
+	return &v1alpha1.WebSpec{
+		TLSSecretRef:   &corev1.LocalObjectReference{Name: "prometheus-tls"},
+		BasicAuthUsers: &corev1.LocalObjectReference{Name: "prometheus-users"},
+	}
+}
+
+// createdSecrets returns secrets created through client, by name
+func createdSecrets(clientSet *fake.Clientset) map[string]*corev1.Secret {
+	res := map[string]*corev1.Secret{}
+	for _, a := range clientSet.Actions() {
+		ca, ok := a.(k8stest.CreateAction)
+		if !ok {
+			continue
+		}
+		if s, ok := ca.GetObject().(*corev1.Secret); ok {
+			res[s.Name] = s
+		}
+	}
+	return res
+}
+
+func webUsers(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-users", Namespace: service2.MonitoringNamespace},
+		Data:       data,
+	}
+}
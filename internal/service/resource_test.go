@@ -35,7 +35,7 @@ func TestItRecognizesAllResourcesCreated(t *testing.T) {
 		t.Fatalf("unexpected resource, expected %d got %d", expected, got)
 	}
 
-	res, err := r.AllCreated()
+	res, err := r.AllCreated(ps)
 	if err != nil {
 		t.Fatalf("unexpected error on checking all resource created, got %v", err)
 	}
@@ -66,6 +66,128 @@ func TestItRemovesAllResources(t *testing.T) {
 	}
 }
 
+func TestItSkipsNotRequiredOptionalResourcesOnCreation(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	fre := &fakeResourceEnforcer{exists: true}
+	foe := &fakeOptionalEnforcer{}
+	r := NewResource(fre, foe)
+	ps := getFakePrometheusServer(namespace, name)
+	if err := r.CreateAll(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on resource creation got %v", err)
+	}
+	if expected, got := 0, foe.creations; expected != got {
+		t.Fatalf("unexpected optional resource creations, expected %d got %d", expected, got)
+	}
+
+	res, err := r.AllCreated(ps)
+	if err != nil {
+		t.Fatalf("unexpected error on checking all resource created, got %v", err)
+	}
+	if !res {
+		t.Error("expected all required resources created")
+	}
+
+	foe.required = true
+	res, err = r.AllCreated(ps)
+	if err != nil {
+		t.Fatalf("unexpected error on checking all resource created, got %v", err)
+	}
+	if res {
+		t.Error("expected required optional resource pending")
+	}
+}
+
+func TestItKeepsInPlaceResourcesOnRemovalCheck(t *testing.T) {
+	fre := &fakeResourceEnforcer{}
+	fip := &fakeInPlaceEnforcer{fakeResourceEnforcer: fakeResourceEnforcer{exists: true}}
+	r := NewResource(fre, fip)
+
+	res, err := r.AllRemoved()
+	if err != nil {
+		t.Fatalf("unexpected error on checking all resource removed, got %v", err)
+	}
+	if !res {
+		t.Error("expected all resources deleted, in place resources are kept")
+	}
+}
+
+type fakeInPlaceEnforcer struct {
+	fakeResourceEnforcer
+}
+
+func (f *fakeInPlaceEnforcer) UpdatesInPlace() bool {
+	return true
+}
+
+type fakeOptionalEnforcer struct {
+	fakeResourceEnforcer
+	required bool
+}
+
+func (f *fakeOptionalEnforcer) IsRequired(obj *v1alpha1.PrometheusServer) bool {
+	return f.required
+}
+
+func TestItReportsStatusFromStatusReporterResources(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	fre := &fakeResourceEnforcer{}
+	fsr := &fakeStatusReporterEnforcer{readyReplicas: 2}
+	r := NewResource(fre, fsr)
+	ps := getFakePrometheusServer(namespace, name)
+	if err := r.ReportStatus(ps); err != nil {
+		t.Fatalf("unexpected error reporting status got %v", err)
+	}
+	if expected, got := fsr.readyReplicas, ps.Status.ReadyReplicas; expected != got {
+		t.Fatalf("ready replicas does not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItDetectsDriftedResourcesFromDriftDetectorEnforcers(t *testing.T) {
+	namespace := "default"
+	name := "prometheus-server-crd"
+	fre := &fakeResourceEnforcer{}
+	fdd := &fakeDriftDetectorEnforcer{drifted: true}
+	r := NewResource(fre, fdd)
+	ps := getFakePrometheusServer(namespace, name)
+	drifted, err := r.AnyDrifted(ps)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift got %v", err)
+	}
+	if !drifted {
+		t.Fatal("expected drifted resources")
+	}
+
+	fdd.drifted = false
+	drifted, err = r.AnyDrifted(ps)
+	if err != nil {
+		t.Fatalf("unexpected error checking drift got %v", err)
+	}
+	if drifted {
+		t.Fatal("unexpected drifted resources")
+	}
+}
+
+type fakeDriftDetectorEnforcer struct {
+	fakeResourceEnforcer
+	drifted bool
+}
+
+func (f *fakeDriftDetectorEnforcer) IsDrifted(obj *v1alpha1.PrometheusServer) (bool, error) {
+	return f.drifted, nil
+}
+
+type fakeStatusReporterEnforcer struct {
+	fakeResourceEnforcer
+	readyReplicas int32
+}
+
+func (f *fakeStatusReporterEnforcer) ReportStatus(obj *v1alpha1.PrometheusServer) error {
+	obj.Status.ReadyReplicas = f.readyReplicas
+	return nil
+}
+
 type fakeResourceEnforcer struct {
 	creations int
 	deletion  int
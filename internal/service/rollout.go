@@ -0,0 +1,18 @@
+package service
+
+import (
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsBlueGreen checks if PrometheusServer changes are rolled out blue green
+func IsBlueGreen(ps *v1alpha1.PrometheusServer) bool {
+	return ps.Spec.Rollout != nil && ps.Spec.Rollout.Strategy == v1alpha1.BlueGreenStrategy
+}
+
+// IsRolledBack checks if PrometheusServer current generation has been rolled back to last known good spec
+func IsRolledBack(ps *v1alpha1.PrometheusServer) bool {
+	c := meta.FindStatusCondition(ps.Status.Conditions, v1alpha1.RolledBack)
+	return c != nil && c.Status == metav1.ConditionTrue && c.ObservedGeneration == ps.Generation
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// actionAnnotations are handled on a fixed order, restarts go first
+var actionAnnotations = []string{
+	v1alpha1.RestartAtAnnotation,
+	v1alpha1.RequestReloadAnnotation,
+	v1alpha1.RequestSnapshotAnnotation,
+}
+
+// runActions runs annotation requested actions once per annotation value
+func runActions(ctx context.Context, r service.ResourceManager, e record.EventRecorder, ps *v1alpha1.PrometheusServer) {
+	for _, a := range actionAnnotations {
+		value := ps.Annotations[a]
+		if value == "" || actionValue(ps, a) == value {
+			continue
+		}
+
+		st := v1alpha1.ActionStatus{Annotation: a, Value: value, Result: v1alpha1.ActionSucceeded, Timestamp: metav1.Now()}
+		if err := runAction(ctx, r, ps, a, value); err != nil {
+			st.Result, st.Message = v1alpha1.ActionFailed, err.Error()
+			e.Eventf(ps, v1.EventTypeWarning, "ActionFailed", "Prometheus Server Namespace %s Name %s action %s=%s failed, error %s", ps.Namespace, ps.Name, a, value, err.Error())
+		} else {
+			e.Eventf(ps, v1.EventTypeNormal, "ActionSucceeded", "Prometheus Server Namespace %s Name %s action %s=%s succeeded", ps.Namespace, ps.Name, a, value)
+		}
+		setAction(ps, st)
+	}
+}
+
+func runAction(ctx context.Context, r service.ResourceManager, ps *v1alpha1.PrometheusServer, annotation, value string) error {
+	switch annotation {
+	case v1alpha1.RestartAtAnnotation:
+		return r.Restart(ctx, ps, value)
+	case v1alpha1.RequestReloadAnnotation:
+		if ps.Spec.AdminAPI == nil {
+			return errors.New("lifecycle API requires adminAPI enabled")
+		}
+		return r.Reload(ctx, ps)
+	default:
+		if ps.Spec.AdminAPI == nil {
+			return errors.New("snapshots require adminAPI enabled")
+		}
+		if ps.Spec.Mode == v1alpha1.AgentMode {
+			return errors.New("agent mode has no TSDB to snapshot")
+		}
+		return r.Snapshot(ctx, ps)
+	}
+}
+
+// actionValue returns last handled annotation value
+func actionValue(ps *v1alpha1.PrometheusServer, annotation string) string {
+	for _, a := range ps.Status.Actions {
+		if a.Annotation == annotation {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// setAction replaces annotation action status
+func setAction(ps *v1alpha1.PrometheusServer, st v1alpha1.ActionStatus) {
+	for i, a := range ps.Status.Actions {
+		if a.Annotation == st.Annotation {
+			ps.Status.Actions[i] = st
+			return
+		}
+	}
+	ps.Status.Actions = append(ps.Status.Actions, st)
+}
@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -13,14 +17,16 @@ type creator struct {
 	finalizer service.Finalizer
 	resource  service.ResourceManager
 	recorder  record.EventRecorder
+	policy    version.Policy
 }
 
-// NewCreator instantiates creation use case states
-func NewCreator(f service.Finalizer, r service.ResourceManager, e record.EventRecorder) service.ConciliatorHandler {
+// NewCreator instantiates creation use case states, PrometheusServers are created once their version is allowed by policy
+func NewCreator(f service.Finalizer, r service.ResourceManager, e record.EventRecorder, p version.Policy) service.ConciliatorHandler {
 	return &creator{
 		finalizer: f,
 		resource:  r,
 		recorder:  e,
+		policy:    p,
 	}
 }
 
@@ -34,6 +40,10 @@ func (c *creator) Empty(ctx context.Context, ps *v1alpha1.PrometheusServer) (new
 		return ps.Status.Phase, nil
 	}
 
+	if !versionAllowed(c.policy, c.recorder, ps) {
+		return ps.Status.Phase, nil
+	}
+
 	return v1alpha1.Initializing, nil
 }
 
@@ -46,6 +56,9 @@ func (c *creator) Initializing(ctx context.Context, ps *v1alpha1.PrometheusServe
 		return ps.Status.Phase, err
 	}
 	c.recorder.Event(ps, v1.EventTypeNormal, "createAllSuccess", "resources created with success")
+	now := metav1.Now()
+	ps.Status.RolloutStartTime = &now
+
 	return v1alpha1.WaitingCreation, nil
 }
 
@@ -53,17 +66,55 @@ func (c *creator) Initializing(ctx context.Context, ps *v1alpha1.PrometheusServe
 func (c *creator) WaitingCreation(ctx context.Context, ps *v1alpha1.PrometheusServer) (newStatus string, err error) {
 	defer waitingCreationProcessed.Inc()
 
-	ok, err := c.resource.AllCreated()
+	if err := c.resource.ReportStatus(ps); err != nil {
+		return ps.Status.Phase, err
+	}
+
+	ok, err := c.resource.AllCreated(ps)
 	if err != nil {
 		return ps.Status.Phase, err
 	}
 	if !ok {
-		return ps.Status.Phase, nil
+		return c.checkRollback(ps)
 	}
 
+	ps.Status.RolloutStartTime = nil
+	recordKnownGood(ps)
+
 	return v1alpha1.Running, nil
 }
 
+// checkRollback rolls back to last known good spec when workload fails to start or it's not ready on deadline
+func (c *creator) checkRollback(ps *v1alpha1.PrometheusServer) (string, error) {
+	if ps.Status.LastKnownGood == nil || service.IsRolledBack(ps) {
+		return ps.Status.Phase, nil
+	}
+
+	reason, err := c.resource.AnyFailed(ps)
+	if err != nil {
+		return ps.Status.Phase, err
+	}
+	if reason == "" {
+		if ps.Status.RolloutStartTime == nil || time.Since(ps.Status.RolloutStartTime.Time) < rolloutDeadline(ps) {
+			return ps.Status.Phase, nil
+		}
+		reason = "workload not ready before deadline"
+	}
+
+	meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.RolledBack,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ps.Generation,
+		Reason:             "Unhealthy",
+		Message:            reason,
+	})
+	ps.Status.RolloutStartTime = nil
+	c.recorder.Eventf(ps, v1.EventTypeWarning, "RolledBack", "Prometheus Server Namespace %s Name %s rolled back to version %s config revision %d, %s",
+		ps.Namespace, ps.Name, ps.Status.LastKnownGood.Version, ps.Status.LastKnownGood.ConfigRevision, reason)
+
+	return v1alpha1.Reloading, nil
+}
+
 // Handlers return creation status handlers
 func (c *creator) Handlers() map[string]service.StateHandler {
 	return map[string]service.StateHandler{
@@ -72,3 +123,29 @@ func (c *creator) Handlers() map[string]service.StateHandler {
 		v1alpha1.WaitingCreation: c.WaitingCreation,
 	}
 }
+
+// recordKnownGood keeps ready workload spec as last known good one
+func recordKnownGood(ps *v1alpha1.PrometheusServer) {
+	if service.IsRolledBack(ps) {
+		return
+	}
+	if meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.RolledBack) {
+		meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+			Type:               v1alpha1.RolledBack,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: ps.Generation,
+			Reason:             "Healthy",
+			Message:            "spec applied",
+		})
+	}
+
+	kg := &v1alpha1.KnownGoodSpec{Version: ps.Spec.Version}
+	if ps.Spec.Image != nil {
+		kg.Repository = ps.Spec.Image.Repository
+		kg.Digest = ps.Spec.Image.Digest
+	}
+	if n := len(ps.Status.ConfigRevisions); n > 0 {
+		kg.ConfigRevision = ps.Status.ConfigRevisions[n-1].Revision
+	}
+	ps.Status.LastKnownGood = kg
+}
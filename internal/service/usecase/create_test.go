@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestItAddsFinalizerAndStaysInTheSameStateOnEmptyState(t *testing.T) {
 	fn := &fakeFinalizer{}
 	rm := &fakeResourceManager{}
-	c := NewCreator(fn, rm, &fakeRecorder{}).(*creator)
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
 	namespace := "default"
 	name := "prometheus-server-crd"
 	ps := getFakePrometheusServer(namespace, name)
@@ -33,7 +36,7 @@ func TestItAddsFinalizerAndStaysInTheSameStateOnEmptyState(t *testing.T) {
 func TestItMovesToInitializingWhenAddedFinalizerOnEmptyState(t *testing.T) {
 	fn := &fakeFinalizer{}
 	rm := &fakeResourceManager{}
-	c := NewCreator(fn, rm, &fakeRecorder{}).(*creator)
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
 	namespace := "default"
 	name := "prometheus-server-crd"
 	ps := getFakePrometheusServer(namespace, name)
@@ -51,7 +54,7 @@ func TestItMovesToInitializingWhenAddedFinalizerOnEmptyState(t *testing.T) {
 func TestItRemainsOnSameStateOnErrorEnsuringFinalizerOnEmptyState(t *testing.T) {
 	fn := &fakeFinalizer{error: errors.New("foo error")}
 	rm := &fakeResourceManager{}
-	c := NewCreator(fn, rm, &fakeRecorder{}).(*creator)
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
 	namespace := "default"
 	name := "prometheus-server-crd"
 	ps := getFakePrometheusServer(namespace, name)
@@ -68,7 +71,7 @@ func TestItRemainsOnSameStateOnErrorEnsuringFinalizerOnEmptyState(t *testing.T)
 func TestItChecksAllResourcesAreCreatedOnWaitingCreationAndJumpsToRunningOnSuccess(t *testing.T) {
 	fn := &fakeFinalizer{}
 	rm := &fakeResourceManager{response: true}
-	c := NewCreator(fn, rm, &fakeRecorder{}).(*creator)
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
 	namespace := "default"
 	name := "prometheus-server-crd"
 	ps := getFakePrometheusServer(namespace, name)
@@ -81,10 +84,27 @@ func TestItChecksAllResourcesAreCreatedOnWaitingCreationAndJumpsToRunningOnSucce
 	}
 }
 
+func TestItReportsResourcesStatusOnWaitingCreation(t *testing.T) {
+	fn := &fakeFinalizer{}
+	rm := &fakeResourceManager{response: false, readyReplicas: 1}
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
+	namespace := "default"
+	name := "prometheus-server-crd"
+	ps := getFakePrometheusServer(namespace, name)
+	ps.Status.Phase = v1alpha1.WaitingCreation
+
+	if _, err := c.WaitingCreation(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := rm.readyReplicas, ps.Status.ReadyReplicas; expected != got {
+		t.Fatalf("ready replicas does not match, expected %d got %d", expected, got)
+	}
+}
+
 func TestItChecksAllResourcesAreCreatedOnWaitingCreationAndRemainsOnStateWhenAllResourcesStillPending(t *testing.T) {
 	fn := &fakeFinalizer{}
 	rm := &fakeResourceManager{response: false}
-	c := NewCreator(fn, rm, &fakeRecorder{}).(*creator)
+	c := NewCreator(fn, rm, &fakeRecorder{}, version.Policy{}).(*creator)
 	namespace := "default"
 	name := "prometheus-server-crd"
 	ps := getFakePrometheusServer(namespace, name)
@@ -99,6 +119,90 @@ func TestItChecksAllResourcesAreCreatedOnWaitingCreationAndRemainsOnStateWhenAll
 	}
 }
 
+func TestItRecordsLastKnownGoodSpecOnReadyWorkload(t *testing.T) {
+	rm := &fakeResourceManager{response: true}
+	c := NewCreator(&fakeFinalizer{}, rm, &fakeRecorder{}, version.Policy{}).(*creator)
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.WaitingCreation
+	ps.Status.ConfigRevisions = []v1alpha1.ConfigRevision{{Revision: 1}, {Revision: 3}}
+	ps.Spec.Image = &v1alpha1.ImageSpec{Repository: "registry.internal/prom/prometheus", Digest: "sha256:foo"}
+
+	newStatus, err := c.WaitingCreation(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := v1alpha1.Running, newStatus; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if kg := ps.Status.LastKnownGood; kg == nil || kg.Version != ps.Spec.Version || kg.ConfigRevision != 3 {
+		t.Errorf("unexpected last known good %v", kg)
+	}
+	if kg := ps.Status.LastKnownGood; kg.Repository != "registry.internal/prom/prometheus" || kg.Digest != "sha256:foo" {
+		t.Errorf("unexpected last known good image %v", kg)
+	}
+}
+
+func TestItRollsBackToLastKnownGoodOnWorkloadFailure(t *testing.T) {
+	rm := &fakeResourceManager{response: false, failure: "pod prometheus-server-0 config load failed"}
+	c := NewCreator(&fakeFinalizer{}, rm, &fakeRecorder{}, version.Policy{}).(*creator)
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Generation = 2
+	ps.Status.Phase = v1alpha1.WaitingCreation
+	ps.Status.LastKnownGood = &v1alpha1.KnownGoodSpec{Version: "v0.0.0", ConfigRevision: 1}
+
+	newStatus, err := c.WaitingCreation(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := v1alpha1.Reloading, newStatus; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	cond := meta.FindStatusCondition(ps.Status.Conditions, v1alpha1.RolledBack)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration != 2 || cond.Message != rm.failure {
+		t.Fatalf("unexpected rolled back condition %v", cond)
+	}
+	if expected, got := "v0.0.1", ps.Spec.Version; expected != got {
+		t.Errorf("spec version mutated, expected %s got %s", expected, got)
+	}
+
+	newStatus, err = c.WaitingCreation(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := v1alpha1.WaitingCreation, newStatus; expected != got {
+		t.Fatalf("rolled back workload rolled back again, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRollsBackOnlyOnDeadlineWhenWorkloadIsNotReady(t *testing.T) {
+	deadline := int32(30)
+	rm := &fakeResourceManager{response: false}
+	c := NewCreator(&fakeFinalizer{}, rm, &fakeRecorder{}, version.Policy{}).(*creator)
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Spec.Rollout = &v1alpha1.RolloutSpec{DeadlineSeconds: &deadline}
+	ps.Status.Phase = v1alpha1.WaitingCreation
+	ps.Status.LastKnownGood = &v1alpha1.KnownGoodSpec{Version: "v0.0.0"}
+	start := metav1.Now()
+	ps.Status.RolloutStartTime = &start
+
+	newStatus, err := c.WaitingCreation(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := v1alpha1.WaitingCreation, newStatus; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+
+	start = metav1.NewTime(time.Now().Add(-time.Minute))
+	newStatus, err = c.WaitingCreation(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting creation state got %v", err)
+	}
+	if expected, got := v1alpha1.Reloading, newStatus; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+}
+
 type fakeFinalizer struct {
 	ensureCalled int
 	addCalled    int
@@ -122,13 +226,23 @@ func (f *fakeFinalizer) Remove(ctx context.Context, ps *v1alpha1.PrometheusServe
 }
 
 type fakeResourceManager struct {
-	removeAll int
-	createAll int
-	error     error
-	response  bool
+	removeAll     int
+	createAll     int
+	error         error
+	response      bool
+	readyReplicas int32
+	drifted       bool
+	updated       bool
+	restarts      []string
+	failure       string
+	snapshots     int
+	rolledOut     []string
+	rolloutReady  bool
+	deleted       []string
+	reloads       int
 }
 
-func (f *fakeResourceManager) AllCreated() (bool, error) {
+func (f *fakeResourceManager) AllCreated(p *v1alpha1.PrometheusServer) (bool, error) {
 	return f.response, f.error
 }
 
@@ -146,6 +260,52 @@ func (f *fakeResourceManager) DeleteAll(ctx context.Context, p *v1alpha1.Prometh
 	return f.error
 }
 
+func (f *fakeResourceManager) ReportStatus(p *v1alpha1.PrometheusServer) error {
+	p.Status.ReadyReplicas = f.readyReplicas
+	return f.error
+}
+
+func (f *fakeResourceManager) AnyDrifted(p *v1alpha1.PrometheusServer) (bool, error) {
+	return f.drifted, f.error
+}
+
+func (f *fakeResourceManager) UpdateConfig(ctx context.Context, p *v1alpha1.PrometheusServer) (bool, error) {
+	return f.updated, f.error
+}
+
+func (f *fakeResourceManager) Restart(ctx context.Context, p *v1alpha1.PrometheusServer, at string) error {
+	f.restarts = append(f.restarts, at)
+	return f.error
+}
+
+func (f *fakeResourceManager) AnyFailed(p *v1alpha1.PrometheusServer) (string, error) {
+	return f.failure, f.error
+}
+
+func (f *fakeResourceManager) Snapshot(ctx context.Context, p *v1alpha1.PrometheusServer) error {
+	f.snapshots++
+	return f.error
+}
+
+func (f *fakeResourceManager) Reload(ctx context.Context, p *v1alpha1.PrometheusServer) error {
+	f.reloads++
+	return f.error
+}
+
+func (f *fakeResourceManager) RollOut(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error {
+	f.rolledOut = append(f.rolledOut, color)
+	return f.error
+}
+
+func (f *fakeResourceManager) RolloutReady(ctx context.Context, p *v1alpha1.PrometheusServer, color string) (bool, error) {
+	return f.rolloutReady, f.error
+}
+
+func (f *fakeResourceManager) DeleteRollout(ctx context.Context, p *v1alpha1.PrometheusServer, color string) error {
+	f.deleted = append(f.deleted, color)
+	return f.error
+}
+
 func getFakePrometheusServer(namespace, name string) *v1alpha1.PrometheusServer {
 	return &v1alpha1.PrometheusServer{
 		TypeMeta: metav1.TypeMeta{},
@@ -29,6 +29,10 @@ func NewDeleter(f service.Finalizer, r service.ResourceManager, e record.EventRe
 func (d *deleter) Terminating(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
 	defer terminatingProcessed.Inc()
 
+	if snapshotBeforeRemoval(ps) {
+		snapshot(ctx, d.resource, d.recorder, ps)
+	}
+
 	if err := d.resource.DeleteAll(ctx, ps); err != nil {
 		d.recorder.Eventf(ps, v1.EventTypeNormal, "DeleteAllError", "Prometheus Server Namespace %s Name %s delete error %s", ps.Namespace, ps.Name, err.Error())
 
@@ -54,3 +54,26 @@ func TestItRemainsOnStateWhenRemovesAllWithResourceManagerError(t *testing.T) {
 		t.Fatalf("new state does not match, expected %s got %s", expected, got)
 	}
 }
+
+func TestItSnapshotsRetainedStorageBeforeRemoval(t *testing.T) {
+	rm := &fakeResourceManager{}
+	dl := NewDeleter(&fakeFinalizer{}, rm, &fakeRecorder{}).(*deleter)
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Spec.AdminAPI = &v1alpha1.AdminAPISpec{SnapshotBeforeChange: true}
+	ps.Spec.Storage = &v1alpha1.StorageSpec{}
+
+	if _, err := dl.Terminating(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on terminating state got %v", err)
+	}
+	if expected, got := 1, rm.snapshots; expected != got {
+		t.Errorf("total snapshots does not match, expected %d got %d", expected, got)
+	}
+
+	ps.Spec.Storage.ReclaimPolicy = v1alpha1.StorageDelete
+	if _, err := dl.Terminating(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on terminating state got %v", err)
+	}
+	if expected, got := 1, rm.snapshots; expected != got {
+		t.Errorf("total snapshots does not match, expected %d got %d", expected, got)
+	}
+}
@@ -45,3 +45,15 @@ var (
 		Help: "The total number of processed events on reloading state",
 	})
 )
+
+var (
+	rollingOutProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "prometheus_usecase_rollout_rolling_out_total",
+		Help: "The total number of processed events on rolling out state",
+	})
+
+	waitingRolloutProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "prometheus_usecase_rollout_waiting_rollout_total",
+		Help: "The total number of processed events on waiting rollout state",
+	})
+)
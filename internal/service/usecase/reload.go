@@ -2,26 +2,35 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 )
 
+// configSyncDelay covers kubelet mounted ConfigMaps sync, one minute period by default plus its cache propagation
+const configSyncDelay = time.Minute * 2
+
 type reloader struct {
 	generation service.Cache
 	resource   service.ResourceManager
 	recorder   record.EventRecorder
+	policy     version.Policy
 }
 
 // NewReloader instantiates reloader use case status handlers
-func NewReloader(c service.Cache, r service.ResourceManager, e record.EventRecorder) service.ConciliatorHandler {
+func NewReloader(c service.Cache, r service.ResourceManager, e record.EventRecorder, p version.Policy) service.ConciliatorHandler {
 	return &reloader{
 		generation: c,
 		resource:   r,
 		recorder:   e,
+		policy:     p,
 	}
 }
 
@@ -29,21 +38,55 @@ func NewReloader(c service.Cache, r service.ResourceManager, e record.EventRecor
 func (r *reloader) Running(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
 	defer runningProcessed.Inc()
 
+	if err := r.resource.ReportStatus(ps); err != nil {
+		return ps.Status.Phase, err
+	}
+	runActions(ctx, r.resource, r.recorder, ps)
+
+	if !versionAllowed(r.policy, r.recorder, ps) {
+		log.Debugf("Prometheus Server Namespace %s Name %s version refused, waiting spec change", ps.Namespace, ps.Name)
+		return ps.Status.Phase, nil
+	}
+
 	g := r.generation.Get(ps.Namespace, ps.Name)
 	log.Infof("Prometheus Server on Running state with generation %d registered is on %d", ps.Generation, g)
 	if g == ps.Generation || g == 0 {
+		if service.IsBlueGreen(ps) && isDegraded(ps) {
+			log.Debugf("Prometheus Server Namespace %s Name %s degraded, waiting spec change", ps.Namespace, ps.Name)
+			return ps.Status.Phase, nil
+		}
+		drifted, err := r.resource.AnyDrifted(ps)
+		if err != nil {
+			return ps.Status.Phase, err
+		}
+		if drifted {
+			r.recorder.Eventf(ps, v1.EventTypeWarning, "Drifted", "Prometheus Server Namespace %s Name %s resources drifted, reloading", ps.Namespace, ps.Name)
+			return reloadPhase(ps), nil
+		}
+		updated, err := r.resource.UpdateConfig(ctx, ps)
+		if err != nil {
+			return ps.Status.Phase, err
+		}
+		if updated {
+			r.recorder.Eventf(ps, v1.EventTypeNormal, "ConfigUpdated", "Prometheus Server Namespace %s Name %s config updated in place", ps.Namespace, ps.Name)
+		}
+		reloadConfig(ctx, r.resource, r.recorder, ps)
 		r.recorder.Eventf(ps, v1.EventTypeNormal, "Running", "Prometheus Server Namespace %s Name %s running", ps.Namespace, ps.Name)
 		return ps.Status.Phase, nil
 	}
 	r.recorder.Eventf(ps, v1.EventTypeNormal, "Reloading", "Prometheus Server Namespace %s Name %s reloading", ps.Namespace, ps.Name)
 
-	return v1alpha1.Reloading, nil
+	return reloadPhase(ps), nil
 }
 
 // Reloading Status handler
 func (r *reloader) Reloading(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
 	defer reloadingProcessed.Inc()
 
+	if snapshotBeforeChange(ps) {
+		snapshot(ctx, r.resource, r.recorder, ps)
+	}
+
 	if err := r.resource.DeleteAll(ctx, ps); err != nil {
 		r.recorder.Eventf(ps, v1.EventTypeWarning, "DeleteAllError", "error %v deleting resources", err.Error())
 
@@ -77,3 +120,52 @@ func (r *reloader) Handlers() map[string]service.StateHandler {
 		v1alpha1.WaitingRemoval: r.WaitingRemoval,
 	}
 }
+
+// reloadConfig reloads or restarts Prometheus once config updated in place is pending reload
+func reloadConfig(ctx context.Context, r service.ResourceManager, e record.EventRecorder, ps *v1alpha1.PrometheusServer) {
+	c := meta.FindStatusCondition(ps.Status.Conditions, v1alpha1.ConfigReloaded)
+	if c == nil || c.Status == metav1.ConditionTrue {
+		return
+	}
+
+	reason := "Reloaded"
+	var err error
+	if ps.Spec.AdminAPI == nil {
+		reason = "Restarted"
+		err = r.Restart(ctx, ps, time.Now().UTC().Format(time.RFC3339))
+	} else {
+		if time.Since(c.LastTransitionTime.Time) < configSyncDelay {
+			return
+		}
+		err = r.Reload(ctx, ps)
+	}
+
+	if err != nil {
+		e.Eventf(ps, v1.EventTypeWarning, "ConfigReloadError", "Prometheus Server Namespace %s Name %s config reload error %s", ps.Namespace, ps.Name, err.Error())
+		meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+			Type:               v1alpha1.ConfigReloaded,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: ps.Generation,
+			Reason:             "ReloadError",
+			Message:            err.Error(),
+		})
+		return
+	}
+
+	e.Eventf(ps, v1.EventTypeNormal, "ConfigReloaded", "Prometheus Server Namespace %s Name %s config reloaded", ps.Namespace, ps.Name)
+	meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConfigReloaded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ps.Generation,
+		Reason:             reason,
+		Message:            "prometheus config reloaded",
+	})
+}
+
+// reloadPhase returns first phase applying PrometheusServer changes, blue green rollouts keep running resources
+func reloadPhase(ps *v1alpha1.PrometheusServer) string {
+	if service.IsBlueGreen(ps) {
+		return v1alpha1.RollingOut
+	}
+	return v1alpha1.Reloading
+}
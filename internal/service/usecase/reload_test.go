@@ -3,8 +3,12 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -16,7 +20,7 @@ func TestItRemainsRunningOnUpdateWithSameGeneration(t *testing.T) {
 	ps := getFakePrometheusServer(namespace, name)
 	ps.Status.Phase = v1alpha1.Running
 	ps.Generation = 1
-	r := NewReloader(c, rm, &fakeRecorder{}).(*reloader)
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
 	newState, err := r.Running(context.Background(), ps)
 	if err != nil {
 		t.Fatalf("unexpected error on terminating state got %v", err)
@@ -35,7 +39,7 @@ func TestItStartsReloadingOnUpdateWithNewerGeneration(t *testing.T) {
 	ps := getFakePrometheusServer(namespace, name)
 	ps.Status.Phase = v1alpha1.Running
 	ps.Generation = 2
-	r := NewReloader(c, rm, &fakeRecorder{}).(*reloader)
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
 	newState, err := r.Running(context.Background(), ps)
 	if err != nil {
 		t.Fatalf("unexpected error on terminating state got %v", err)
@@ -46,6 +50,81 @@ func TestItStartsReloadingOnUpdateWithNewerGeneration(t *testing.T) {
 	}
 }
 
+func TestItStartsReloadingOnRunningWithDriftedResources(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{drifted: true}
+	namespace := "default"
+	name := "prometheus-server-crd"
+	ps := getFakePrometheusServer(namespace, name)
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	newState, err := r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+
+	if expected, got := v1alpha1.Reloading, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItReloadsConfigUpdatedInPlaceOnceMountedConfigIsSynced(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	ps.Spec.AdminAPI = &v1alpha1.AdminAPISpec{}
+	ps.Status.Conditions = []metav1.Condition{{Type: v1alpha1.ConfigReloaded, Status: metav1.ConditionFalse, Reason: "ReloadPending", LastTransitionTime: metav1.Now()}}
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+
+	newState, err := r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 0, rm.reloads; expected != got {
+		t.Fatalf("reloads do not match, expected %d got %d", expected, got)
+	}
+
+	ps.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-configSyncDelay))
+	if _, err := r.Running(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := 1, rm.reloads; expected != got {
+		t.Fatalf("reloads do not match, expected %d got %d", expected, got)
+	}
+	if !meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.ConfigReloaded) {
+		t.Error("expected config reloaded condition")
+	}
+}
+
+func TestItRestartsPodsOnConfigUpdatedInPlaceWithoutLifecycleAPI(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{updated: true}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	ps.Status.Conditions = []metav1.Condition{{Type: v1alpha1.ConfigReloaded, Status: metav1.ConditionFalse, Reason: "ReloadPending", LastTransitionTime: metav1.Now()}}
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+
+	if _, err := r.Running(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := 1, len(rm.restarts); expected != got {
+		t.Fatalf("restarts do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 0, rm.removeAll; expected != got {
+		t.Errorf("removals do not match, expected %d got %d", expected, got)
+	}
+	if !meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.ConfigReloaded) {
+		t.Error("expected config reloaded condition")
+	}
+}
+
 func TestItRemovesAllResourcesAndJumpsToWaitingRemovalState(t *testing.T) {
 	c := &fakeCache{value: 1}
 	rm := &fakeResourceManager{}
@@ -54,7 +133,7 @@ func TestItRemovesAllResourcesAndJumpsToWaitingRemovalState(t *testing.T) {
 	ps := getFakePrometheusServer(namespace, name)
 	ps.Status.Phase = v1alpha1.Reloading
 
-	r := NewReloader(c, rm, &fakeRecorder{}).(*reloader)
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
 	newState, err := r.Reloading(context.Background(), ps)
 	if err != nil {
 		t.Fatalf("unexpected error on terminating state got %v", err)
@@ -77,7 +156,7 @@ func TestItChecksAllResourcesAreRemovedAndJumpsToInitializeToForceResourceRecrea
 	ps := getFakePrometheusServer(namespace, name)
 	ps.Status.Phase = v1alpha1.WaitingRemoval
 
-	r := NewReloader(c, rm, &fakeRecorder{}).(*reloader)
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
 	newState, err := r.WaitingRemoval(context.Background(), ps)
 	if err != nil {
 		t.Fatalf("unexpected error on terminating state got %v", err)
@@ -88,6 +167,26 @@ func TestItChecksAllResourcesAreRemovedAndJumpsToInitializeToForceResourceRecrea
 	}
 }
 
+func TestItSnapshotsBeforeRemovingResourcesOnReloading(t *testing.T) {
+	rm := &fakeResourceManager{}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Reloading
+	ps.Spec.AdminAPI = &v1alpha1.AdminAPISpec{SnapshotBeforeChange: true}
+	ps.Spec.Storage = &v1alpha1.StorageSpec{}
+
+	r := NewReloader(&fakeCache{value: 1}, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	if _, err := r.Reloading(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on reloading state got %v", err)
+	}
+
+	if expected, got := 1, rm.snapshots; expected != got {
+		t.Errorf("total snapshots does not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 1, rm.removeAll; expected != got {
+		t.Errorf("total calls does not match, expected %d got %d", expected, got)
+	}
+}
+
 type fakeCache struct {
 	value   int64
 	set     int
@@ -115,3 +214,121 @@ func (f *fakeRecorder) Eventf(object runtime.Object, eventtype, reason, messageF
 
 func (f *fakeRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
 }
+
+func TestItRunsAnnotationRequestedActionsOncePerValue(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	ps.Spec.AdminAPI = &v1alpha1.AdminAPISpec{}
+	ps.Annotations = map[string]string{
+		v1alpha1.RestartAtAnnotation:     "2022-05-10T10:10:10Z",
+		v1alpha1.RequestReloadAnnotation: "1",
+	}
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Running(context.Background(), ps); err != nil {
+			t.Fatalf("unexpected error on running state got %v", err)
+		}
+	}
+
+	if expected, got := 1, len(rm.restarts); expected != got {
+		t.Fatalf("restarts do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "2022-05-10T10:10:10Z", rm.restarts[0]; expected != got {
+		t.Errorf("restart value does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, rm.reloads; expected != got {
+		t.Errorf("reloads do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 2, len(ps.Status.Actions); expected != got {
+		t.Fatalf("actions do not match, expected %d got %d", expected, got)
+	}
+
+	ps.Annotations[v1alpha1.RequestReloadAnnotation] = "2"
+	if _, err := r.Running(context.Background(), ps); err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := 2, rm.reloads; expected != got {
+		t.Errorf("reloads do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := "2", ps.Status.Actions[1].Value; expected != got {
+		t.Errorf("acknowledged value does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItReportsFailedActionWithoutRetryingIt(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	ps.Annotations = map[string]string{v1alpha1.RequestSnapshotAnnotation: "1"}
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Running(context.Background(), ps); err != nil {
+			t.Fatalf("unexpected error on running state got %v", err)
+		}
+	}
+
+	if expected, got := 0, rm.snapshots; expected != got {
+		t.Errorf("snapshots do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := 1, len(ps.Status.Actions); expected != got {
+		t.Fatalf("actions do not match, expected %d got %d", expected, got)
+	}
+	if expected, got := v1alpha1.ActionFailed, ps.Status.Actions[0].Result; expected != got {
+		t.Errorf("action result does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItKeepsRunningOnRefusedVersionDowngrade(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{drifted: true}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 2
+	ps.Spec.Version = "v2.34.0"
+	ps.Status.LastKnownGood = &v1alpha1.KnownGoodSpec{Version: "v2.35.0"}
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	newState, err := r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.VersionAllowed) {
+		t.Error("expected version refused condition")
+	}
+
+	ps.Annotations = map[string]string{v1alpha1.AllowDowngradeAnnotation: "true"}
+	newState, err = r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+	if expected, got := v1alpha1.Reloading, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if !meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.VersionAllowed) {
+		t.Error("expected version allowed condition")
+	}
+}
+
+func TestItDoesNotInitializeVersionsOutOfAllowedRange(t *testing.T) {
+	p, err := version.NewPolicy(">=v2.30.0")
+	if err != nil {
+		t.Fatalf("unexpected error parsing policy %v", err)
+	}
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Finalizers = []string{v1alpha1.Name}
+	c := NewCreator(&fakeFinalizer{}, &fakeResourceManager{}, &fakeRecorder{}, p).(*creator)
+	newState, err := c.Empty(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on empty state got %v", err)
+	}
+	if expected, got := ps.Status.Phase, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const defaultRolloutDeadline = time.Second * 600
+
+type rollout struct {
+	resource      service.ResourceManager
+	recorder      record.EventRecorder
+	podsReachable bool
+}
+
+// NewRollout instantiates blue green rollout use case status handlers
+func NewRollout(r service.ResourceManager, e record.EventRecorder, podsReachable bool) service.ConciliatorHandler {
+	return &rollout{
+		resource:      r,
+		recorder:      e,
+		podsReachable: podsReachable,
+	}
+}
+
+// RollingOut Status handler, candidate workload is created on the inactive color
+func (r *rollout) RollingOut(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
+	defer rollingOutProcessed.Inc()
+
+	if !r.podsReachable {
+		return r.reject(ctx, ps)
+	}
+
+	candidate := v1alpha1.GreenColor
+	if ps.Status.Color == v1alpha1.GreenColor {
+		candidate = v1alpha1.BlueColor
+	}
+	if ps.Status.Candidate == "" {
+		now := metav1.Now()
+		ps.Status.Candidate = candidate
+		ps.Status.RolloutStartTime = &now
+	}
+
+	if err := r.resource.RollOut(ctx, ps, ps.Status.Candidate); err != nil {
+		r.recorder.Eventf(ps, v1.EventTypeWarning, "RolloutError", "error %v rolling out %s resources", err.Error(), ps.Status.Candidate)
+
+		return ps.Status.Phase, err
+	}
+	r.recorder.Eventf(ps, v1.EventTypeNormal, "RollingOut", "Prometheus Server Namespace %s Name %s rolling out %s", ps.Namespace, ps.Name, ps.Status.Candidate)
+
+	return v1alpha1.WaitingRollout, nil
+}
+
+// WaitingRollout Status handler, switches to candidate once it's ready or removes it on deadline
+func (r *rollout) WaitingRollout(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
+	defer waitingRolloutProcessed.Inc()
+
+	candidate := ps.Status.Candidate
+	if candidate == "" {
+		return v1alpha1.RollingOut, nil
+	}
+
+	if !r.podsReachable {
+		return r.reject(ctx, ps)
+	}
+
+	ready, err := r.resource.RolloutReady(ctx, ps, candidate)
+	if err != nil {
+		return ps.Status.Phase, err
+	}
+
+	if ready {
+		previous := v1alpha1.BlueColor
+		if ps.Status.Color != "" {
+			previous = ps.Status.Color
+		}
+		ps.Status.Color = candidate
+		if err := r.resource.CreateAll(ctx, ps); err != nil {
+			ps.Status.Color = previous
+			return ps.Status.Phase, err
+		}
+		if err := r.resource.DeleteRollout(ctx, ps, previous); err != nil {
+			return ps.Status.Phase, err
+		}
+
+		r.completeRollout(ps, metav1.ConditionFalse, "RolledOut", "candidate "+candidate+" rolled out")
+		recordKnownGood(ps)
+		r.recorder.Eventf(ps, v1.EventTypeNormal, "RolledOut", "Prometheus Server Namespace %s Name %s switched to %s", ps.Namespace, ps.Name, candidate)
+
+		return v1alpha1.Running, nil
+	}
+
+	if ps.Status.RolloutStartTime != nil && time.Since(ps.Status.RolloutStartTime.Time) < rolloutDeadline(ps) {
+		log.Debugf("Prometheus Server Namespace %s Name %s waiting %s rollout", ps.Namespace, ps.Name, candidate)
+		return ps.Status.Phase, nil
+	}
+
+	if err := r.resource.DeleteRollout(ctx, ps, candidate); err != nil {
+		return ps.Status.Phase, err
+	}
+
+	r.completeRollout(ps, metav1.ConditionTrue, "RolloutTimeout", "candidate "+candidate+" not ready before deadline, running workload kept")
+	r.recorder.Eventf(ps, v1.EventTypeWarning, "RolloutTimeout", "Prometheus Server Namespace %s Name %s candidate %s not ready before deadline", ps.Namespace, ps.Name, candidate)
+
+	return v1alpha1.Running, nil
+}
+
+// Handlers return rollout status handlers
+func (r *rollout) Handlers() map[string]service.StateHandler {
+	return map[string]service.StateHandler{
+		v1alpha1.RollingOut:     r.RollingOut,
+		v1alpha1.WaitingRollout: r.WaitingRollout,
+	}
+}
+
+// reject removes any rolled out candidate and reports blue green rollout as unsupported, running workload is kept
+func (r *rollout) reject(ctx context.Context, ps *v1alpha1.PrometheusServer) (string, error) {
+	if ps.Status.Candidate != "" {
+		if err := r.resource.DeleteRollout(ctx, ps, ps.Status.Candidate); err != nil {
+			return ps.Status.Phase, err
+		}
+	}
+
+	r.completeRollout(ps, metav1.ConditionTrue, "BlueGreenUnsupported", "candidate pods are not reachable from operator, blue green rollout rejected")
+	r.recorder.Eventf(ps, v1.EventTypeWarning, "BlueGreenUnsupported", "Prometheus Server Namespace %s Name %s blue green rollout requires operator running in cluster", ps.Namespace, ps.Name)
+
+	return v1alpha1.Running, nil
+}
+
+// completeRollout clears rollout candidate and reports its result on Degraded condition
+func (r *rollout) completeRollout(ps *v1alpha1.PrometheusServer, status metav1.ConditionStatus, reason, message string) {
+	ps.Status.Candidate = ""
+	ps.Status.RolloutStartTime = nil
+	meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.Degraded,
+		Status:             status,
+		ObservedGeneration: ps.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// rolloutDeadline returns time new workload has to become ready
+func rolloutDeadline(ps *v1alpha1.PrometheusServer) time.Duration {
+	if ps.Spec.Rollout == nil || ps.Spec.Rollout.DeadlineSeconds == nil {
+		return defaultRolloutDeadline
+	}
+	return time.Duration(*ps.Spec.Rollout.DeadlineSeconds) * time.Second
+}
+
+// isDegraded checks if current generation rollout failed
+func isDegraded(ps *v1alpha1.PrometheusServer) bool {
+	c := meta.FindStatusCondition(ps.Status.Conditions, v1alpha1.Degraded)
+	return c != nil && c.Status == metav1.ConditionTrue && c.ObservedGeneration == ps.Generation
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestItStartsRollingOutOnUpdateWithNewerGenerationOnBlueGreenStrategy(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 2
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	newState, err := r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+
+	if expected, got := v1alpha1.RollingOut, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItSkipsDriftDetectionOnDegradedGeneration(t *testing.T) {
+	c := &fakeCache{value: 1}
+	rm := &fakeResourceManager{drifted: true}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.Running
+	ps.Generation = 1
+	meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{Type: v1alpha1.Degraded, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: "RolloutTimeout"})
+	r := NewReloader(c, rm, &fakeRecorder{}, version.Policy{}).(*reloader)
+	newState, err := r.Running(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on running state got %v", err)
+	}
+
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+}
+
+func TestItRollsOutCandidateOnInactiveColor(t *testing.T) {
+	rm := &fakeResourceManager{}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.RollingOut
+	r := NewRollout(rm, &fakeRecorder{}, true).(*rollout)
+	newState, err := r.RollingOut(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on rolling out state got %v", err)
+	}
+
+	if expected, got := v1alpha1.WaitingRollout, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := v1alpha1.GreenColor, ps.Status.Candidate; expected != got {
+		t.Errorf("candidate does not match, expected %s got %s", expected, got)
+	}
+	if ps.Status.RolloutStartTime == nil {
+		t.Error("expected rollout start time")
+	}
+	if expected, got := 1, len(rm.rolledOut); expected != got {
+		t.Fatalf("total rollouts do not match, expected %d got %d", expected, got)
+	}
+}
+
+func TestItRejectsBlueGreenRolloutWhenPodsAreNotReachable(t *testing.T) {
+	rm := &fakeResourceManager{}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.RollingOut
+	r := NewRollout(rm, &fakeRecorder{}, false).(*rollout)
+	newState, err := r.RollingOut(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on rolling out state got %v", err)
+	}
+
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 0, len(rm.rolledOut); expected != got {
+		t.Fatalf("total rollouts do not match, expected %d got %d", expected, got)
+	}
+	if !isDegraded(ps) {
+		t.Error("expected degraded generation")
+	}
+	if ps.Status.Candidate != "" {
+		t.Errorf("expected empty candidate, got %s", ps.Status.Candidate)
+	}
+}
+
+func TestItSwitchesToReadyCandidateAndRemovesPreviousColor(t *testing.T) {
+	rm := &fakeResourceManager{rolloutReady: true}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.WaitingRollout
+	ps.Status.Color = v1alpha1.GreenColor
+	ps.Status.Candidate = v1alpha1.BlueColor
+	now := metav1.Now()
+	ps.Status.RolloutStartTime = &now
+	r := NewRollout(rm, &fakeRecorder{}, true).(*rollout)
+	newState, err := r.WaitingRollout(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting rollout state got %v", err)
+	}
+
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := v1alpha1.BlueColor, ps.Status.Color; expected != got {
+		t.Errorf("color does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 1, rm.createAll; expected != got {
+		t.Errorf("total calls does not match, expected %d got %d", expected, got)
+	}
+	if len(rm.deleted) != 1 || rm.deleted[0] != v1alpha1.GreenColor {
+		t.Errorf("expected green removal, got %v", rm.deleted)
+	}
+	if ps.Status.Candidate != "" || meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.Degraded) {
+		t.Errorf("unexpected status %v", ps.Status)
+	}
+}
+
+func TestItRemovesCandidateAndDegradesOnRolloutDeadline(t *testing.T) {
+	rm := &fakeResourceManager{rolloutReady: false}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.WaitingRollout
+	ps.Status.Candidate = v1alpha1.GreenColor
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	ps.Status.RolloutStartTime = &start
+	r := NewRollout(rm, &fakeRecorder{}, true).(*rollout)
+	newState, err := r.WaitingRollout(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting rollout state got %v", err)
+	}
+
+	if expected, got := v1alpha1.Running, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if len(rm.deleted) != 1 || rm.deleted[0] != v1alpha1.GreenColor {
+		t.Errorf("expected candidate removal, got %v", rm.deleted)
+	}
+	if expected, got := 0, rm.createAll; expected != got {
+		t.Errorf("total calls does not match, expected %d got %d", expected, got)
+	}
+	if ps.Status.Color != "" || !meta.IsStatusConditionTrue(ps.Status.Conditions, v1alpha1.Degraded) {
+		t.Errorf("unexpected status %v", ps.Status)
+	}
+}
+
+func TestItKeepsWaitingRolloutBeforeDeadline(t *testing.T) {
+	rm := &fakeResourceManager{rolloutReady: false}
+	ps := getFakeBlueGreenPrometheusServer()
+	ps.Status.Phase = v1alpha1.WaitingRollout
+	ps.Status.Candidate = v1alpha1.GreenColor
+	now := metav1.Now()
+	ps.Status.RolloutStartTime = &now
+	r := NewRollout(rm, &fakeRecorder{}, true).(*rollout)
+	newState, err := r.WaitingRollout(context.Background(), ps)
+	if err != nil {
+		t.Fatalf("unexpected error on waiting rollout state got %v", err)
+	}
+
+	if expected, got := v1alpha1.WaitingRollout, newState; expected != got {
+		t.Fatalf("new state does not match, expected %s got %s", expected, got)
+	}
+	if expected, got := 0, len(rm.deleted); expected != got {
+		t.Errorf("total removals do not match, expected %d got %d", expected, got)
+	}
+}
+
+func getFakeBlueGreenPrometheusServer() *v1alpha1.PrometheusServer {
+	deadline := int32(30)
+	ps := getFakePrometheusServer("default", "prometheus-server-crd")
+	ps.Spec.Rollout = &v1alpha1.RolloutSpec{Strategy: v1alpha1.BlueGreenStrategy, DeadlineSeconds: &deadline}
+	return ps
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marcosQuesada/prometheus-operator/internal/service"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// snapshot takes TSDB snapshots before a destructive action, failures are reported but never block it
+func snapshot(ctx context.Context, r service.ResourceManager, e record.EventRecorder, ps *v1alpha1.PrometheusServer) {
+	err := r.Snapshot(ctx, ps)
+	if err != nil {
+		e.Eventf(ps, v1.EventTypeWarning, "SnapshotError", "Prometheus Server Namespace %s Name %s snapshot error %s", ps.Namespace, ps.Name, err.Error())
+	}
+
+	var names []string
+	for _, s := range ps.Status.Snapshots {
+		names = append(names, s.Pod+"/"+s.Name)
+	}
+	if len(names) > 0 {
+		e.Eventf(ps, v1.EventTypeNormal, "Snapshot", "Prometheus Server Namespace %s Name %s snapshots %s taken", ps.Namespace, ps.Name, strings.Join(names, ", "))
+	}
+}
+
+// snapshotBeforeChange checks if TSDB is snapshotted before workload removal
+func snapshotBeforeChange(ps *v1alpha1.PrometheusServer) bool {
+	return ps.Spec.AdminAPI != nil && ps.Spec.AdminAPI.SnapshotBeforeChange && ps.Spec.Mode != v1alpha1.AgentMode && ps.Spec.Storage != nil
+}
+
+// snapshotBeforeRemoval checks if TSDB is snapshotted before PrometheusServer removal, storage must be retained
+func snapshotBeforeRemoval(ps *v1alpha1.PrometheusServer) bool {
+	return snapshotBeforeChange(ps) && ps.Spec.Storage.ReclaimPolicy != v1alpha1.StorageDelete
+}
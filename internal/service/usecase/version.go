@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"github.com/marcosQuesada/prometheus-operator/internal/service/version"
+	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// versionAllowed checks spec version against operator version policy, result is reported on VersionAllowed condition
+func versionAllowed(p version.Policy, e record.EventRecorder, ps *v1alpha1.PrometheusServer) bool {
+	var previous string
+	if ps.Status.LastKnownGood != nil {
+		previous = ps.Status.LastKnownGood.Version
+	}
+
+	err := p.Check(ps.Spec.Version, previous, ps.Annotations[v1alpha1.AllowDowngradeAnnotation] == "true")
+	if err == nil {
+		meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+			Type:               v1alpha1.VersionAllowed,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: ps.Generation,
+			Reason:             "VersionAllowed",
+			Message:            "version " + ps.Spec.Version + " allowed",
+		})
+		return true
+	}
+
+	c := meta.FindStatusCondition(ps.Status.Conditions, v1alpha1.VersionAllowed)
+	if c == nil || c.Status != metav1.ConditionFalse || c.ObservedGeneration != ps.Generation {
+		e.Eventf(ps, v1.EventTypeWarning, "VersionRefused", "Prometheus Server Namespace %s Name %s %s", ps.Namespace, ps.Name, err.Error())
+	}
+	meta.SetStatusCondition(&ps.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.VersionAllowed,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: ps.Generation,
+		Reason:             "VersionRefused",
+		Message:            err.Error(),
+	})
+	return false
+}
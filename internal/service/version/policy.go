@@ -0,0 +1,110 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+var operators = []string{">=", "<=", ">", "<", "="}
+
+// constraint is a single version comparison, as >=v2.30.0
+type constraint struct {
+	operator string
+	version  string
+}
+
+func (c constraint) matches(v string) bool {
+	r := semver.Compare(v, c.version)
+	switch c.operator {
+	case ">=":
+		return r >= 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	case "<":
+		return r < 0
+	default:
+		return r == 0
+	}
+}
+
+func (c constraint) String() string {
+	return c.operator + c.version
+}
+
+// Policy defines Prometheus versions allowed by the operator
+type Policy struct {
+	constraints []constraint
+}
+
+// NewPolicy parses allowed version range, as space separated constraints (">=v2.30.0 <v3.0.0")
+func NewPolicy(allowed string) (Policy, error) {
+	var p Policy
+	for _, field := range strings.Fields(allowed) {
+		c, err := parseConstraint(field)
+		if err != nil {
+			return Policy{}, err
+		}
+		p.constraints = append(p.constraints, c)
+	}
+	return p, nil
+}
+
+// Check validates next version against allowed range, and against previous one when it's defined
+func (p Policy) Check(next, previous string, allowDowngrade bool) error {
+	v := Canonical(next)
+	if !semver.IsValid(v) {
+		return fmt.Errorf("version %s is not a semantic version", next)
+	}
+
+	for _, c := range p.constraints {
+		if !c.matches(v) {
+			return fmt.Errorf("version %s out of allowed range %s", next, p)
+		}
+	}
+
+	if previous == "" || allowDowngrade {
+		return nil
+	}
+	prev := Canonical(previous)
+	if !semver.IsValid(prev) {
+		return nil
+	}
+	if semver.Compare(semver.MajorMinor(v), semver.MajorMinor(prev)) < 0 {
+		return fmt.Errorf("version %s downgrades running %s", next, previous)
+	}
+	return nil
+}
+
+func (p Policy) String() string {
+	var res []string
+	for _, c := range p.constraints {
+		res = append(res, c.String())
+	}
+	return strings.Join(res, " ")
+}
+
+// Canonical adds semver v prefix when it's missing, Prometheus image tags already include it
+func Canonical(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+func parseConstraint(s string) (constraint, error) {
+	for _, op := range operators {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		v := Canonical(strings.TrimPrefix(s, op))
+		if !semver.IsValid(v) {
+			return constraint{}, fmt.Errorf("invalid version constraint %s", s)
+		}
+		return constraint{operator: op, version: v}, nil
+	}
+	return constraint{}, fmt.Errorf("invalid version constraint %s, expected operator on %v", s, operators)
+}
@@ -0,0 +1,39 @@
+package version
+
+import "testing"
+
+func TestItChecksVersionsAgainstPolicy(t *testing.T) {
+	p, err := NewPolicy(">=v2.30.0 <3.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error parsing policy %v", err)
+	}
+
+	tests := []struct {
+		next, previous string
+		allowDowngrade bool
+		valid          bool
+	}{
+		{next: "v2.35.0", valid: true},
+		{next: "2.35.0", valid: true},
+		{next: "latest", valid: false},
+		{next: "v2.29.1", valid: false},
+		{next: "v3.0.0", valid: false},
+		{next: "v2.36.0", previous: "v2.35.0", valid: true},
+		{next: "v2.35.0", previous: "v2.35.2", valid: true},
+		{next: "v2.34.0", previous: "v2.35.0", valid: false},
+		{next: "v2.34.0", previous: "v2.35.0", allowDowngrade: true, valid: true},
+	}
+	for _, tc := range tests {
+		if err := p.Check(tc.next, tc.previous, tc.allowDowngrade); (err == nil) != tc.valid {
+			t.Errorf("version %s from %s valid does not match, expected %t got error %v", tc.next, tc.previous, tc.valid, err)
+		}
+	}
+}
+
+func TestItRejectsInvalidPolicyConstraints(t *testing.T) {
+	for _, allowed := range []string{"v2.30.0", ">=foo", "~v2"} {
+		if _, err := NewPolicy(allowed); err == nil {
+			t.Errorf("expected error parsing %s", allowed)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -31,17 +34,396 @@ const (
 	Terminating = "TERMINATING"
 	// Terminated happens after processing Terminate, final exit state
 	Terminated = "TERMINATED"
+	// RollingOut happens on blue green rollouts, candidate workload is created side by side with the running one
+	RollingOut = "ROLLING_OUT"
+	// WaitingRollout waits until candidate workload is ready and healthy, or its deadline is exceeded
+	WaitingRollout = "WAITING_ROLLOUT"
+)
+
+const (
+	// RestartAtAnnotation restarts Prometheus pods once per distinct value, as a timestamp
+	RestartAtAnnotation = GroupName + "/restart-at"
+	// RequestReloadAnnotation reloads Prometheus config once per distinct value, lifecycle API must be enabled
+	RequestReloadAnnotation = GroupName + "/request-reload"
+	// RequestSnapshotAnnotation takes TSDB snapshots once per distinct value, admin API must be enabled
+	RequestSnapshotAnnotation = GroupName + "/request-snapshot"
+	// AllowDowngradeAnnotation set to true allows version downgrades across major or minor versions
+	AllowDowngradeAnnotation = GroupName + "/allow-downgrade"
+)
+
+const (
+	// ActionSucceeded reports an annotation requested action run successfully
+	ActionSucceeded = "Succeeded"
+	// ActionFailed reports an annotation requested action failure, it's not retried until annotation value changes
+	ActionFailed = "Failed"
+)
+
+const (
+	// ConfigReloaded condition reports if config updated in place has been reloaded by Prometheus
+	ConfigReloaded = "ConfigReloaded"
+	// ConfigRendered condition reports if PrometheusServer config has been rendered, render errors are on its message
+	ConfigRendered = "ConfigRendered"
+	// Degraded condition reports a failed rollout, running workload is kept on previous spec
+	Degraded = "Degraded"
+	// RolledBack condition reports last known good spec restored after an unhealthy change, user spec is kept
+	RolledBack = "RolledBack"
+	// StorageSynced condition reports if storage claims match storage spec, changes not applied are on its message
+	StorageSynced = "StorageSynced"
+	// VersionAllowed condition reports if spec version is allowed by operator version policy
+	VersionAllowed = "VersionAllowed"
+)
+
+const (
+	// ServerMode runs Prometheus as a regular server, with local TSDB, rules and alerting
+	ServerMode = "server"
+	// AgentMode runs Prometheus as an agent, it only scrapes and remote writes
+	AgentMode = "agent"
+)
+
+const (
+	// ClusterScope grants Prometheus cluster wide read access through ClusterRole and ClusterRoleBinding
+	ClusterScope = "cluster"
+	// NamespacesScope grants Prometheus read access through Role and RoleBinding on listed namespaces only
+	NamespacesScope = "namespaces"
+)
+
+const (
+	// StorageRetain keeps Prometheus storage volume on PrometheusServer removal
+	StorageRetain = "Retain"
+	// StorageDelete removes Prometheus storage volume on PrometheusServer removal
+	StorageDelete = "Delete"
+)
+
+const (
+	// RecreateStrategy removes running resources before creating them again from the new spec
+	RecreateStrategy = "recreate"
+	// BlueGreenStrategy brings up a candidate workload side by side, traffic is switched once it's ready and healthy
+	BlueGreenStrategy = "blueGreen"
+)
+
+const (
+	// BlueColor is the default workload color, its resources keep unsuffixed names
+	BlueColor = "blue"
+	// GreenColor workload resources are suffixed by its color
+	GreenColor = "green"
 )
 
 // Status defines the observed state of Worker
 type Status struct {
-	Phase string `json:"phase,omitempty"`
+	Phase         string `json:"phase,omitempty"`
+	Replicas      int32  `json:"replicas,omitempty"`
+	ReadyReplicas int32  `json:"readyReplicas,omitempty"`
+	// Image reports the Prometheus image used by running workload
+	Image string `json:"image,omitempty"`
+	// Shards reports each shard workload replicas
+	Shards []ShardStatus `json:"shards,omitempty"`
+	// Conditions reports PrometheusServer conditions, as config rendering
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Color reports active workload color on blue green rollouts, blue when empty
+	Color string `json:"color,omitempty"`
+	// Candidate reports workload color being rolled out and rollout start time
+	Candidate        string       `json:"candidate,omitempty"`
+	RolloutStartTime *metav1.Time `json:"rolloutStartTime,omitempty"`
+	// LastKnownGood reports the last spec whose workload became ready, it's restored on automatic rollbacks
+	LastKnownGood *KnownGoodSpec `json:"lastKnownGood,omitempty"`
+	// ConfigRevisions lists applied config revisions, from the oldest to the last applied one
+	ConfigRevisions []ConfigRevision `json:"configRevisions,omitempty"`
+	// Snapshots reports TSDB snapshots taken by the last snapshot request, one per pod
+	Snapshots []SnapshotStatus `json:"snapshots,omitempty"`
+	// Actions acknowledges annotation requested actions, reporting last handled value per annotation
+	Actions []ActionStatus `json:"actions,omitempty"`
+}
+
+// ActionStatus reports an annotation requested action outcome
+type ActionStatus struct {
+	Annotation string      `json:"annotation"`
+	Value      string      `json:"value"`
+	Result     string      `json:"result"`
+	Message    string      `json:"message,omitempty"`
+	Timestamp  metav1.Time `json:"timestamp"`
+}
+
+// SnapshotStatus reports a pod TSDB snapshot, it's stored under snapshots directory on pod storage volume
+type SnapshotStatus struct {
+	Pod       string      `json:"pod"`
+	Name      string      `json:"name"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// KnownGoodSpec references Prometheus version, image and config revision of a healthy workload
+type KnownGoodSpec struct {
+	Version        string `json:"version"`
+	Repository     string `json:"repository,omitempty"`
+	Digest         string `json:"digest,omitempty"`
+	ConfigRevision int64  `json:"configRevision,omitempty"`
+}
+
+// ConfigRevision references an applied config kept on an immutable ConfigMap named after its hash
+type ConfigRevision struct {
+	Revision  int64       `json:"revision"`
+	Hash      string      `json:"hash"`
+	Name      string      `json:"name"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// ShardStatus defines the observed state of a Prometheus shard workload
+type ShardStatus struct {
+	Shard         int32 `json:"shard"`
+	Replicas      int32 `json:"replicas,omitempty"`
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
 }
 
 // PrometheusServerSpec defines the desired state of PrometheusServer
 type PrometheusServerSpec struct {
 	Version string `json:"version"`
 	Config  string `json:"config"`
+	// TemplateConfig renders config as a Go text/template, with PrometheusServer metadata, operator variables and Secret file placeholders
+	TemplateConfig bool `json:"templateConfig,omitempty"`
+	// RevisionHistoryLimit defines applied config revisions kept, defaults to 10
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// RollbackTo applies config from the given revision instead of config field, while it's defined
+	RollbackTo *int64 `json:"rollbackTo,omitempty"`
+	// ConfigFragments selects ConfigMaps holding scrape_configs, rule_files and remote_write entries to append
+	ConfigFragments *metav1.LabelSelector `json:"configFragments,omitempty"`
+	// Federation scrapes /federate endpoint from selected PrometheusServers
+	Federation *FederationSpec `json:"federation,omitempty"`
+	// Image overrides operator level Prometheus image settings
+	Image *ImageSpec `json:"image,omitempty"`
+	// Mode is server (default) or agent, agent mode has no local storage and does not support rules nor alerting
+	Mode string `json:"mode,omitempty"`
+	// Replicas defines Prometheus Server instances, each replica scrapes the same targets (defaults to 1)
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Shards splits scrape targets across Prometheus workloads by address hash, each shard runs all replicas (defaults to 1)
+	Shards  *int32       `json:"shards,omitempty"`
+	Storage *StorageSpec `json:"storage,omitempty"`
+	// Retention defines how long to retain samples in storage, as 15d (Prometheus default applies when empty)
+	Retention string `json:"retention,omitempty"`
+	// RetentionSize defines the maximum number of bytes of storage blocks to retain, as 10GB
+	RetentionSize string                      `json:"retentionSize,omitempty"`
+	Resources     corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Scheduling constraints applied to Prometheus pods
+	NodeSelector      map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations       []corev1.Toleration `json:"tolerations,omitempty"`
+	Affinity          *corev1.Affinity    `json:"affinity,omitempty"`
+	PriorityClassName string              `json:"priorityClassName,omitempty"`
+	// SecurityContext defines pod security context, defaults to non root Prometheus user
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// ContainerSecurityContext defines Prometheus container security context, defaults to no privilege escalation
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// RemoteWrite entries are appended to Prometheus remote_write config, referenced Secrets are mounted
+	RemoteWrite []RemoteWriteSpec `json:"remoteWrite,omitempty"`
+	// RemoteRead entries are appended to Prometheus remote_read config, referenced Secrets are mounted
+	RemoteRead []RemoteReadSpec `json:"remoteRead,omitempty"`
+	// ValidateConfig adds an init container which checks Prometheus config with promtool before starting Prometheus
+	ValidateConfig bool `json:"validateConfig,omitempty"`
+	// Sidecars are appended to Prometheus pod containers, as Thanos sidecar or log shippers
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+	// Volumes are appended to Prometheus pod volumes, sidecars and Prometheus container can mount them
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts are appended to Prometheus container volume mounts
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// ServiceAccountName uses an existing service account, a dedicated one is created when it's empty
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// RBAC defines Prometheus api access scope, cluster wide by default
+	RBAC *RBACSpec `json:"rbac,omitempty"`
+	// Service customizes Prometheus Service, changes are applied in place
+	Service *ServiceSpec `json:"service,omitempty"`
+	// Ingress exposes Prometheus UI, no Ingress is created when it's not defined
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+	// NetworkPolicy restricts Prometheus ingress traffic, no NetworkPolicy is created when it's not defined
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// PodDisruptionBudget protects Prometheus replicas from voluntary disruptions, no PodDisruptionBudget is created when it's not defined
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// Rollout defines how spec changes are applied, running resources are recreated when it's not defined
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+	// AdminAPI enables Prometheus admin and lifecycle APIs, admin API is ignored on agent mode
+	AdminAPI *AdminAPISpec `json:"adminAPI,omitempty"`
+	// Web secures Prometheus http endpoint with TLS and basic auth, plain http is served when it's not defined
+	Web *WebSpec `json:"web,omitempty"`
+	// Components deploys bundled exporters, their scrape jobs are added to Prometheus config
+	Components *ComponentsSpec `json:"components,omitempty"`
+}
+
+// RolloutSpec defines PrometheusServer rollout strategy
+type RolloutSpec struct {
+	// Strategy is recreate (default) or blueGreen
+	Strategy string `json:"strategy,omitempty"`
+	// DeadlineSeconds candidate workload has to become ready and healthy, defaults to 600
+	DeadlineSeconds *int32 `json:"deadlineSeconds,omitempty"`
+}
+
+// AdminAPISpec defines Prometheus admin API usage
+type AdminAPISpec struct {
+	// SnapshotBeforeChange takes a TSDB snapshot from each ready pod before recreating or removing the workload
+	SnapshotBeforeChange bool `json:"snapshotBeforeChange,omitempty"`
+}
+
+// WebSpec references Secrets on monitoring namespace used to build Prometheus web config
+type WebSpec struct {
+	// TLSSecretRef references a kubernetes.io/tls Secret, Prometheus serves https from its tls.crt and tls.key
+	TLSSecretRef *corev1.LocalObjectReference `json:"tlsSecretRef,omitempty"`
+	// BasicAuthUsers references a Secret whose keys are user names and values their bcrypt hashed passwords
+	BasicAuthUsers *corev1.LocalObjectReference `json:"basicAuthUsers,omitempty"`
+}
+
+// ComponentsSpec defines bundled exporters, each one is only deployed when it's defined
+type ComponentsSpec struct {
+	// NodeExporter runs node-exporter as a DaemonSet on each node
+	NodeExporter *ComponentSpec `json:"nodeExporter,omitempty"`
+	// KubeStateMetrics runs kube-state-metrics as a Deployment on kube-system namespace
+	KubeStateMetrics *ComponentSpec `json:"kubeStateMetrics,omitempty"`
+}
+
+// ComponentSpec defines a bundled exporter workload
+type ComponentSpec struct {
+	// Image overrides component default image
+	Image     string                      `json:"image,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// NetworkPolicySpec defines allowed sources to Prometheus http port, any source matching one of them is allowed
+type NetworkPolicySpec struct {
+	// Namespaces allowed by name
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector and PodSelector allow sources matching both of them
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	PodSelector       *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines Prometheus PodDisruptionBudget
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable overrides available replicas, defaults to one less than replicas
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// RemoteWriteSpec defines a Prometheus remote write endpoint
+type RemoteWriteSpec struct {
+	URL                 string          `json:"url"`
+	Name                string          `json:"name,omitempty"`
+	RemoteTimeout       string          `json:"remoteTimeout,omitempty"`
+	QueueConfig         *QueueConfig    `json:"queueConfig,omitempty"`
+	WriteRelabelConfigs []RelabelConfig `json:"writeRelabelConfigs,omitempty"`
+	BasicAuth           *BasicAuth      `json:"basicAuth,omitempty"`
+	BearerToken         *SecretKeyRef   `json:"bearerToken,omitempty"`
+	TLSConfig           *TLSConfig      `json:"tlsConfig,omitempty"`
+}
+
+// RemoteReadSpec defines a Prometheus remote read endpoint
+type RemoteReadSpec struct {
+	URL              string            `json:"url"`
+	Name             string            `json:"name,omitempty"`
+	RemoteTimeout    string            `json:"remoteTimeout,omitempty"`
+	ReadRecent       bool              `json:"readRecent,omitempty"`
+	RequiredMatchers map[string]string `json:"requiredMatchers,omitempty"`
+	BasicAuth        *BasicAuth        `json:"basicAuth,omitempty"`
+	BearerToken      *SecretKeyRef     `json:"bearerToken,omitempty"`
+	TLSConfig        *TLSConfig        `json:"tlsConfig,omitempty"`
+}
+
+// QueueConfig tunes remote write queues, Prometheus defaults apply on empty fields
+type QueueConfig struct {
+	Capacity          int    `json:"capacity,omitempty"`
+	MinShards         int    `json:"minShards,omitempty"`
+	MaxShards         int    `json:"maxShards,omitempty"`
+	MaxSamplesPerSend int    `json:"maxSamplesPerSend,omitempty"`
+	BatchSendDeadline string `json:"batchSendDeadline,omitempty"`
+	MinBackoff        string `json:"minBackoff,omitempty"`
+	MaxBackoff        string `json:"maxBackoff,omitempty"`
+}
+
+// RelabelConfig defines a Prometheus relabeling rule
+type RelabelConfig struct {
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	TargetLabel  string   `json:"targetLabel,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	Modulus      uint64   `json:"modulus,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Action       string   `json:"action,omitempty"`
+}
+
+// BasicAuth defines basic authentication, password is read from a Secret
+type BasicAuth struct {
+	Username string       `json:"username"`
+	Password SecretKeyRef `json:"password"`
+}
+
+// TLSConfig defines TLS client config, certificates and key are read from Secrets
+type TLSConfig struct {
+	CA                 *SecretKeyRef `json:"ca,omitempty"`
+	Cert               *SecretKeyRef `json:"cert,omitempty"`
+	Key                *SecretKeyRef `json:"key,omitempty"`
+	ServerName         string        `json:"serverName,omitempty"`
+	InsecureSkipVerify bool          `json:"insecureSkipVerify,omitempty"`
+}
+
+// SecretKeyRef references a key on a Secret from Prometheus namespace
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// RBACSpec defines Prometheus api access scope
+type RBACSpec struct {
+	// Scope is cluster (default) or namespaces
+	Scope string `json:"scope,omitempty"`
+	// Namespaces Prometheus is allowed to discover targets from, required on namespaces scope
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ServiceSpec defines Prometheus Service, defaults to ClusterIP on port 8080
+type ServiceSpec struct {
+	Type corev1.ServiceType `json:"type,omitempty"`
+	Port int32              `json:"port,omitempty"`
+	// NodePort is only applied on NodePort and LoadBalancer types, it's allocated by the cluster when empty
+	NodePort                 int32    `json:"nodePort,omitempty"`
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// Annotations are merged over default prometheus.io scrape annotations
+	Annotations     map[string]string      `json:"annotations,omitempty"`
+	Labels          map[string]string      `json:"labels,omitempty"`
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+}
+
+// IngressSpec defines Prometheus UI Ingress, Prometheus external url and route prefix are built from it
+type IngressSpec struct {
+	Host string `json:"host"`
+	// Path defines Prometheus route prefix (defaults to /)
+	Path string `json:"path,omitempty"`
+	// TLSSecretName enables TLS termination on Ingress using the referenced secret
+	TLSSecretName    string            `json:"tlsSecretName,omitempty"`
+	IngressClassName *string           `json:"ingressClassName,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
+
+// StorageSpec defines Prometheus TSDB persistent storage, EmptyDir is used when it's not defined
+type StorageSpec struct {
+	StorageClassName *string                           `json:"storageClassName,omitempty"`
+	Size             resource.Quantity                 `json:"size"`
+	AccessMode       corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	// ReclaimPolicy defines what happens with the volume on PrometheusServer removal, Retain or Delete (defaults to Retain)
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+}
+
+// FederationSpec selects federated PrometheusServers, they are scraped through their own managed Service
+type FederationSpec struct {
+	// From selects PrometheusServers on any namespace, federating PrometheusServer is excluded
+	From *metav1.LabelSelector `json:"from"`
+	// Match defines federated series selectors, as match[] parameters
+	Match []string `json:"match"`
+	// BasicAuth credentials are used on selected PrometheusServers requiring basic auth
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+	// TLSConfig is used on selected PrometheusServers serving https, server name defaults to their Service host
+	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+}
+
+// ImageSpec defines Prometheus image source, empty fields fallback to operator level settings
+type ImageSpec struct {
+	// Repository defines Prometheus image repository, as registry.internal/prom/prometheus
+	Repository string `json:"repository,omitempty"`
+	// Digest pins Prometheus image, as sha256:..., it takes precedence over version tag
+	Digest      string                        `json:"digest,omitempty"`
+	PullPolicy  corev1.PullPolicy             `json:"pullPolicy,omitempty"`
+	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
 }
 
 // +genclient
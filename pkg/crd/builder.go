@@ -36,6 +36,21 @@ func (c *manager) Create(ctx context.Context, cr *v1.CustomResourceDefinition) e
 	return c.waitCRDAccepted(ctx, cr.Name)
 }
 
+// Get returns registered CRD
+func (c *manager) Get(ctx context.Context, resourceName string) (*v1.CustomResourceDefinition, error) {
+	return c.apiExtensionsClientSet.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, resourceName, metav1.GetOptions{})
+}
+
+// Update replaces registered CRD and waits until it's accepted again
+func (c *manager) Update(ctx context.Context, cr *v1.CustomResourceDefinition) error {
+	_, err := c.apiExtensionsClientSet.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, cr, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return c.waitCRDAccepted(ctx, cr.Name)
+}
+
 // IsAccepted checks if CRD is accepted
 func (c *manager) IsAccepted(ctx context.Context, resourceName string) (bool, error) {
 	cr, err := c.apiExtensionsClientSet.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, resourceName, metav1.GetOptions{})
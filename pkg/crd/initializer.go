@@ -6,7 +6,9 @@ import (
 
 	"github.com/marcosQuesada/prometheus-operator/pkg/crd/apis/prometheusserver/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -14,6 +16,8 @@ import (
 type Initializer interface {
 	Create(ctx context.Context, cr *v1.CustomResourceDefinition) error
 	IsAccepted(ctx context.Context, resourceName string) (bool, error)
+	Get(ctx context.Context, resourceName string) (*v1.CustomResourceDefinition, error)
+	Update(ctx context.Context, cr *v1.CustomResourceDefinition) error
 }
 
 type Builder struct {
@@ -27,7 +31,7 @@ func NewBuilder(i Initializer) *Builder {
 	}
 }
 
-// EnsureCRDRegistration ensures CRD is created, if it didn't it will force creation
+// EnsureCRDRegistration ensures CRD is created, registered CRDs are updated when they differ from built ones
 func (b *Builder) EnsureCRDRegistration(ctx context.Context) error {
 	log.Debug("Ensuring crd is registered")
 	acc, err := b.initializer.IsAccepted(ctx, v1alpha1.Name)
@@ -36,19 +40,44 @@ func (b *Builder) EnsureCRDRegistration(ctx context.Context) error {
 	}
 
 	if acc {
+		if err := b.update(ctx); err != nil {
+			return fmt.Errorf("unable to update crd, error %w", err)
+		}
 		return nil
 	}
 
-	if err := b.create(context.Background()); err != nil {
+	log.Debug("Creating Prometheus Server CRD")
+	if err := b.initializer.Create(context.Background(), b.build()); err != nil {
 		return fmt.Errorf("unable to initialize crd, error %w", err)
 	}
 
 	return nil
 }
 
-// Create defines PrometheusServer CRD resource
-func (b *Builder) create(ctx context.Context) error {
-	log.Debug("Creating Prometheus Server CRD")
+// update replaces registered CRD versions, schema included, when they differ from the built ones
+func (b *Builder) update(ctx context.Context) error {
+	cr, err := b.initializer.Get(ctx, v1alpha1.Name)
+	if err != nil {
+		return err
+	}
+
+	desired := b.build()
+	if equality.Semantic.DeepEqual(cr.Spec.Versions, desired.Spec.Versions) {
+		return nil
+	}
+
+	log.Info("Updating Prometheus Server CRD schema")
+	cr.Spec.Versions = desired.Spec.Versions
+	return b.initializer.Update(ctx, cr)
+}
+
+// build defines PrometheusServer CRD resource
+func (b *Builder) build() *v1.CustomResourceDefinition {
+	minReplicas := float64(1)
+	minMatch := int64(1)
+	minDeadline := float64(1)
+	minRevision := float64(1)
+	preserveUnknownFields := true
 	cr := &v1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: v1alpha1.Name,
@@ -71,7 +100,202 @@ func (b *Builder) create(ctx context.Context) error {
 									Type: "object",
 									Properties: map[string]v1.JSONSchemaProps{
 										"version": {Type: "string"},
+										"mode":    {Type: "string", Enum: enum(v1alpha1.ServerMode, v1alpha1.AgentMode)},
 										"config":  {Type: "string"},
+										"image": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"repository": {Type: "string"},
+												"digest":     {Type: "string", Pattern: "^[A-Za-z0-9_+.-]+:[A-Fa-f0-9]+$"},
+												"pullPolicy": {
+													Type: "string",
+													Enum: enum(string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)),
+												},
+												"pullSecrets": {
+													Type: "array",
+													Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{
+														Type:       "object",
+														Properties: map[string]v1.JSONSchemaProps{"name": {Type: "string"}},
+													}},
+												},
+											},
+										},
+										"replicas": {
+											Type:    "integer",
+											Format:  "int32",
+											Minimum: &minReplicas,
+										},
+										"shards": {
+											Type:    "integer",
+											Format:  "int32",
+											Minimum: &minReplicas,
+										},
+										"storage": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"storageClassName": {Type: "string"},
+												"size":             quantity(),
+												"accessMode":       {Type: "string"},
+												"reclaimPolicy": {
+													Type: "string",
+													Enum: enum(v1alpha1.StorageRetain, v1alpha1.StorageDelete),
+												},
+											},
+											Required: []string{"size"},
+										},
+										"retention":     {Type: "string"},
+										"retentionSize": {Type: "string"},
+										"resources":     {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+										"nodeSelector": {
+											Type:                 "object",
+											AdditionalProperties: &v1.JSONSchemaPropsOrBool{Schema: &v1.JSONSchemaProps{Type: "string"}},
+										},
+										"tolerations": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}},
+										},
+										"affinity":                 {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+										"priorityClassName":        {Type: "string"},
+										"securityContext":          {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+										"containerSecurityContext": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+										"remoteWrite": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: remoteWriteSchema()},
+										},
+										"remoteRead": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: remoteReadSchema()},
+										},
+										"validateConfig":       {Type: "boolean"},
+										"templateConfig":       {Type: "boolean"},
+										"revisionHistoryLimit": {Type: "integer", Format: "int32", Minimum: &minRevision},
+										"rollbackTo":           {Type: "integer", Format: "int64", Minimum: &minRevision},
+										"configFragments":      {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+										"federation": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"from": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+												"match": {
+													Type:     "array",
+													MinItems: &minMatch,
+													Items:    &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+												"basicAuth": basicAuthSchema(),
+												"tlsConfig": tlsConfigSchema(),
+											},
+											Required: []string{"from", "match"},
+										},
+										"sidecars": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}},
+										},
+										"volumes": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}},
+										},
+										"volumeMounts": {
+											Type:  "array",
+											Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}},
+										},
+										"serviceAccountName": {Type: "string"},
+										"rbac": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"scope": {
+													Type: "string",
+													Enum: enum(v1alpha1.ClusterScope, v1alpha1.NamespacesScope),
+												},
+												"namespaces": {
+													Type:  "array",
+													Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+											},
+										},
+										"service": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"type": {
+													Type: "string",
+													Enum: enum(string(corev1.ServiceTypeClusterIP), string(corev1.ServiceTypeNodePort), string(corev1.ServiceTypeLoadBalancer)),
+												},
+												"port":     {Type: "integer", Format: "int32"},
+												"nodePort": {Type: "integer", Format: "int32"},
+												"loadBalancerSourceRanges": {
+													Type:  "array",
+													Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+												"annotations": {
+													Type:                 "object",
+													AdditionalProperties: &v1.JSONSchemaPropsOrBool{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+												"labels": {
+													Type:                 "object",
+													AdditionalProperties: &v1.JSONSchemaPropsOrBool{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+												"sessionAffinity": {
+													Type: "string",
+													Enum: enum(string(corev1.ServiceAffinityNone), string(corev1.ServiceAffinityClientIP)),
+												},
+											},
+										},
+										"networkPolicy": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"namespaces": {
+													Type:  "array",
+													Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+												"namespaceSelector": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+												"podSelector":       {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+											},
+										},
+										"rollout": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"strategy":        {Type: "string", Enum: enum(v1alpha1.RecreateStrategy, v1alpha1.BlueGreenStrategy)},
+												"deadlineSeconds": {Type: "integer", Minimum: &minDeadline},
+											},
+										},
+										"adminAPI": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"snapshotBeforeChange": {Type: "boolean"},
+											},
+										},
+										"web": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"tlsSecretRef":   secretReferenceSchema(),
+												"basicAuthUsers": secretReferenceSchema(),
+											},
+										},
+										"components": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"nodeExporter":     componentSchema(),
+												"kubeStateMetrics": componentSchema(),
+											},
+										},
+										"podDisruptionBudget": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"minAvailable": {XIntOrString: true},
+											},
+										},
+										"ingress": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"host":             {Type: "string"},
+												"path":             {Type: "string"},
+												"tlsSecretName":    {Type: "string"},
+												"ingressClassName": {Type: "string"},
+												"annotations": {
+													Type:                 "object",
+													AdditionalProperties: &v1.JSONSchemaPropsOrBool{Schema: &v1.JSONSchemaProps{Type: "string"}},
+												},
+											},
+											Required: []string{"host"},
+										},
 									},
 									Required: []string{"version", "config"},
 								},
@@ -81,6 +305,93 @@ func (b *Builder) create(ctx context.Context) error {
 										"phase": {
 											Type: "string",
 										},
+										"replicas":      {Type: "integer", Format: "int32"},
+										"readyReplicas": {Type: "integer", Format: "int32"},
+										"image":         {Type: "string"},
+										"shards": {
+											Type: "array",
+											Items: &v1.JSONSchemaPropsOrArray{
+												Schema: &v1.JSONSchemaProps{
+													Type: "object",
+													Properties: map[string]v1.JSONSchemaProps{
+														"shard":         {Type: "integer", Format: "int32"},
+														"replicas":      {Type: "integer", Format: "int32"},
+														"readyReplicas": {Type: "integer", Format: "int32"},
+													},
+												},
+											},
+										},
+										"conditions": {
+											Type: "array",
+											Items: &v1.JSONSchemaPropsOrArray{
+												Schema: &v1.JSONSchemaProps{
+													Type: "object",
+													Properties: map[string]v1.JSONSchemaProps{
+														"type":               {Type: "string"},
+														"status":             {Type: "string"},
+														"observedGeneration": {Type: "integer", Format: "int64"},
+														"lastTransitionTime": {Type: "string", Format: "date-time"},
+														"reason":             {Type: "string"},
+														"message":            {Type: "string"},
+													},
+													Required: []string{"type", "status", "lastTransitionTime", "reason", "message"},
+												},
+											},
+										},
+										"color":            {Type: "string"},
+										"candidate":        {Type: "string"},
+										"rolloutStartTime": {Type: "string", Format: "date-time"},
+										"snapshots": {
+											Type: "array",
+											Items: &v1.JSONSchemaPropsOrArray{
+												Schema: &v1.JSONSchemaProps{
+													Type: "object",
+													Properties: map[string]v1.JSONSchemaProps{
+														"pod":       {Type: "string"},
+														"name":      {Type: "string"},
+														"timestamp": {Type: "string", Format: "date-time"},
+													},
+												},
+											},
+										},
+										"actions": {
+											Type: "array",
+											Items: &v1.JSONSchemaPropsOrArray{
+												Schema: &v1.JSONSchemaProps{
+													Type: "object",
+													Properties: map[string]v1.JSONSchemaProps{
+														"annotation": {Type: "string"},
+														"value":      {Type: "string"},
+														"result":     {Type: "string"},
+														"message":    {Type: "string"},
+														"timestamp":  {Type: "string", Format: "date-time"},
+													},
+												},
+											},
+										},
+										"lastKnownGood": {
+											Type: "object",
+											Properties: map[string]v1.JSONSchemaProps{
+												"version":        {Type: "string"},
+												"repository":     {Type: "string"},
+												"digest":         {Type: "string"},
+												"configRevision": {Type: "integer", Format: "int64"},
+											},
+										},
+										"configRevisions": {
+											Type: "array",
+											Items: &v1.JSONSchemaPropsOrArray{
+												Schema: &v1.JSONSchemaProps{
+													Type: "object",
+													Properties: map[string]v1.JSONSchemaProps{
+														"revision":  {Type: "integer", Format: "int64"},
+														"hash":      {Type: "string"},
+														"name":      {Type: "string"},
+														"timestamp": {Type: "string", Format: "date-time"},
+													},
+												},
+											},
+										},
 									},
 								},
 							},
@@ -115,5 +426,141 @@ func (b *Builder) create(ctx context.Context) error {
 		},
 	}
 
-	return b.initializer.Create(ctx, cr)
+	return cr
+}
+
+// quantity accepts integer and string quantities, as Kubernetes resource quantities do
+func quantity() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		XIntOrString: true,
+		AnyOf:        []v1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+	}
+}
+
+// remoteWriteSchema defines remote write entry schema
+func remoteWriteSchema() *v1.JSONSchemaProps {
+	props := remoteEndpointProperties()
+	props["queueConfig"] = v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"capacity":          {Type: "integer"},
+			"minShards":         {Type: "integer"},
+			"maxShards":         {Type: "integer"},
+			"maxSamplesPerSend": {Type: "integer"},
+			"batchSendDeadline": {Type: "string"},
+			"minBackoff":        {Type: "string"},
+			"maxBackoff":        {Type: "string"},
+		},
+	}
+	props["writeRelabelConfigs"] = v1.JSONSchemaProps{
+		Type: "array",
+		Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]v1.JSONSchemaProps{
+				"sourceLabels": {Type: "array", Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "string"}}},
+				"separator":    {Type: "string"},
+				"targetLabel":  {Type: "string"},
+				"regex":        {Type: "string"},
+				"modulus":      {Type: "integer"},
+				"replacement":  {Type: "string"},
+				"action":       {Type: "string"},
+			},
+		}},
+	}
+
+	return &v1.JSONSchemaProps{Type: "object", Properties: props, Required: []string{"url"}}
+}
+
+// remoteReadSchema defines remote read entry schema
+func remoteReadSchema() *v1.JSONSchemaProps {
+	props := remoteEndpointProperties()
+	props["readRecent"] = v1.JSONSchemaProps{Type: "boolean"}
+	props["requiredMatchers"] = v1.JSONSchemaProps{
+		Type:                 "object",
+		AdditionalProperties: &v1.JSONSchemaPropsOrBool{Schema: &v1.JSONSchemaProps{Type: "string"}},
+	}
+
+	return &v1.JSONSchemaProps{Type: "object", Properties: props, Required: []string{"url"}}
+}
+
+// remoteEndpointProperties defines remote write and read shared properties, credentials reference Secrets
+func remoteEndpointProperties() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"url":           {Type: "string"},
+		"name":          {Type: "string"},
+		"remoteTimeout": {Type: "string"},
+		"basicAuth":     basicAuthSchema(),
+		"bearerToken":   secretKeyRefSchema(),
+		"tlsConfig":     tlsConfigSchema(),
+	}
+}
+
+// basicAuthSchema defines basic authentication, password is read from a Secret key
+func basicAuthSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"username": {Type: "string"},
+			"password": secretKeyRefSchema(),
+		},
+		Required: []string{"username", "password"},
+	}
+}
+
+// tlsConfigSchema defines TLS client config, certificates and key are read from Secret keys
+func tlsConfigSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"ca":                 secretKeyRefSchema(),
+			"cert":               secretKeyRefSchema(),
+			"key":                secretKeyRefSchema(),
+			"serverName":         {Type: "string"},
+			"insecureSkipVerify": {Type: "boolean"},
+		},
+	}
+}
+
+// secretKeyRefSchema defines a Secret key reference
+func secretKeyRefSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"name": {Type: "string"},
+			"key":  {Type: "string"},
+		},
+		Required: []string{"name", "key"},
+	}
+}
+
+// secretReferenceSchema defines a Secret reference by name
+func secretReferenceSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]v1.JSONSchemaProps{
+			"name": {Type: "string"},
+		},
+	}
+}
+
+// componentSchema defines bundled exporter schema
+func componentSchema() v1.JSONSchemaProps {
+	preserveUnknownFields := true
+	return v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"image":     {Type: "string"},
+			"resources": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+		},
+	}
+}
+
+// enum builds schema enum values from allowed strings
+func enum(values ...string) []v1.JSON {
+	res := make([]v1.JSON, 0, len(values))
+	for _, v := range values {
+		res = append(res, v1.JSON{Raw: []byte(fmt.Sprintf("%q", v))})
+	}
+	return res
 }
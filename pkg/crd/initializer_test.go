@@ -22,6 +22,7 @@ func TestEnsureCRDRegisteredOnNonRegisteredCrd(t *testing.T) {
 func TestEnsureCRDRegisteredOnAlreadyRegisteredCrd(t *testing.T) {
 	ini := &fakeInitializer{result: true}
 	b := NewBuilder(ini)
+	ini.stored = b.build()
 	if err := b.EnsureCRDRegistration(context.Background()); err != nil {
 		t.Errorf("unable to ensure crd registered, error %v", err)
 	}
@@ -31,10 +32,32 @@ func TestEnsureCRDRegisteredOnAlreadyRegisteredCrd(t *testing.T) {
 	}
 }
 
+func TestEnsureCRDRegisteredUpdatesOutdatedSchema(t *testing.T) {
+	ini := &fakeInitializer{result: true}
+	b := NewBuilder(ini)
+	ini.stored = b.build()
+	delete(ini.stored.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties, "status")
+
+	for i := 0; i < 2; i++ {
+		if err := b.EnsureCRDRegistration(context.Background()); err != nil {
+			t.Fatalf("unable to ensure crd registered, error %v", err)
+		}
+	}
+
+	if expected, got := 1, ini.updates; expected != got {
+		t.Errorf("total updates do not match, expected %d got %d", expected, got)
+	}
+	if _, ok := ini.stored.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["status"]; !ok {
+		t.Error("expected status schema restored")
+	}
+}
+
 type fakeInitializer struct {
 	result   bool
 	error    error
 	creation int
+	stored   *v1.CustomResourceDefinition
+	updates  int
 }
 
 func (f *fakeInitializer) Create(ctx context.Context, cr *v1.CustomResourceDefinition) error {
@@ -45,3 +68,13 @@ func (f *fakeInitializer) Create(ctx context.Context, cr *v1.CustomResourceDefin
 func (f *fakeInitializer) IsAccepted(ctx context.Context, resourceName string) (bool, error) {
 	return f.result, f.error
 }
+
+func (f *fakeInitializer) Get(ctx context.Context, resourceName string) (*v1.CustomResourceDefinition, error) {
+	return f.stored, f.error
+}
+
+func (f *fakeInitializer) Update(ctx context.Context, cr *v1.CustomResourceDefinition) error {
+	f.updates++
+	f.stored = cr
+	return f.error
+}